@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestArchiveTodo(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "keep"}, Todo{ID: "b", Text: "archive"})
+
+	var archived struct{ ArchiveTodo Todo }
+	mustExecute(t, `mutation{archiveTodo(id:"b"){id,archived}}`, &archived)
+	if !archived.ArchiveTodo.Archived {
+		t.Errorf("archiveTodo = %+v, want archived", archived.ArchiveTodo)
+	}
+
+	var data struct{ TodoList []Todo }
+	mustExecute(t, `{todoList{id}}`, &data)
+	if ids := todoIDs(data.TodoList); len(ids) != 1 || ids[0] != "a" {
+		t.Errorf("todoList = %v, want the archived todo hidden", ids)
+	}
+	mustExecute(t, `{todoList(includeArchived:true){id}}`, &data)
+	if ids := todoIDs(data.TodoList); len(ids) != 2 {
+		t.Errorf("todoList(includeArchived) = %v, want both", ids)
+	}
+
+	if code := errorCode(t, execute(t, `mutation{archiveTodo(id:"nope"){id}}`)); code != ErrCodeNotFound {
+		t.Errorf("unknown id: code = %q, want %s", code, ErrCodeNotFound)
+	}
+}
+
+func TestLastTodoSkipsArchived(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "first"}, Todo{ID: "b", Text: "archived", Archived: true})
+	var data struct{ LastTodo *Todo }
+	mustExecute(t, `{lastTodo{id}}`, &data)
+	if data.LastTodo == nil || data.LastTodo.ID != "a" {
+		t.Errorf("lastTodo = %+v, want a", data.LastTodo)
+	}
+
+	resetTodos(t, Todo{ID: "b", Text: "archived", Archived: true})
+	mustExecute(t, `{lastTodo{id}}`, &data)
+	if data.LastTodo != nil {
+		t.Errorf("lastTodo = %+v, want null when every todo is archived", data.LastTodo)
+	}
+}
+
+func TestStaleTodosSkipsArchived(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-30 * 24 * time.Hour)
+	todos := []Todo{
+		{ID: "a", UpdatedAt: old},
+		{ID: "b", UpdatedAt: old, Archived: true},
+	}
+	if ids := todoIDs(StaleTodos(todos, now, 14*24*time.Hour)); len(ids) != 1 || ids[0] != "a" {
+		t.Errorf("stale = %v, want only a", ids)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestTodosByAssignee(t *testing.T) {
+	resetTodos(t)
+	for _, query := range []string{
+		`mutation{createTodo(text:"review",task:"t",assignee:"alice"){id}}`,
+		`mutation{createTodo(text:"deploy",task:"t",assignee:"bob"){id}}`,
+		`mutation{createTodo(text:"plan",task:"t",assignee:"alice"){id}}`,
+		`mutation{createTodo(text:"nobody's",task:"t"){id}}`,
+	} {
+		mustExecute(t, query, &struct{}{})
+	}
+
+	tests := []struct {
+		assignee string
+		want     []string
+	}{
+		{"alice", []string{"review", "plan"}},
+		{"bob", []string{"deploy"}},
+		{"", []string{"nobody's"}},
+		{"carol", []string{}},
+	}
+	for _, tt := range tests {
+		var data struct{ TodosByAssignee []Todo }
+		mustExecute(t, `{todosByAssignee(assignee:"`+tt.assignee+`"){text,assignee}}`, &data)
+		if len(data.TodosByAssignee) != len(tt.want) {
+			t.Errorf("todosByAssignee(%q) = %v, want %v", tt.assignee, data.TodosByAssignee, tt.want)
+			continue
+		}
+		for i, todo := range data.TodosByAssignee {
+			if todo.Text != tt.want[i] || todo.Assignee != tt.assignee {
+				t.Errorf("todosByAssignee(%q)[%d] = %+v, want %q", tt.assignee, i, todo, tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"mime"
+	"strings"
+	"time"
+)
+
+// Attachment is the metadata of a file attached to a todo. The file content
+// itself is not stored by this service.
+type Attachment struct {
+	ID          string    `json:"id"`
+	Filename    string    `json:"filename"`
+	Size        int       `json:"size"`
+	ContentType string    `json:"contentType"`
+	AddedAt     time.Time `json:"addedAt"`
+}
+
+// maxAttachmentSize is the largest attachment size in bytes addAttachment accepts.
+var maxAttachmentSize = 10 << 20
+
+// NewAttachment validates attachment metadata and returns the attachment to store.
+func NewAttachment(filename string, size int, contentType string, now time.Time) (Attachment, error) {
+	filename = strings.TrimSpace(filename)
+	if filename == "" {
+		return Attachment{}, NewCodedError(ErrCodeValidationError, "filename must not be empty")
+	}
+	if size < 0 {
+		return Attachment{}, NewCodedError(ErrCodeValidationError, "size must not be negative")
+	}
+	if size > maxAttachmentSize {
+		return Attachment{}, NewCodedError(ErrCodeValidationError, "attachment of %d bytes exceeds the limit of %d bytes", size, maxAttachmentSize)
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || !strings.Contains(mediaType, "/") {
+		return Attachment{}, NewCodedError(ErrCodeValidationError, "invalid content type %q", contentType)
+	}
+	return Attachment{
+		ID:          NewID("attachment"),
+		Filename:    filename,
+		Size:        size,
+		ContentType: contentType,
+		AddedAt:     now,
+	}, nil
+}
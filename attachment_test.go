@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAddAttachment(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "with files", Version: 1})
+	var data struct {
+		AddAttachment struct {
+			AttachmentCount int
+			Attachments     []Attachment
+		}
+	}
+	for i, name := range []string{"plan.pdf", "notes.txt"} {
+		mustExecute(t, fmt.Sprintf(`mutation{addAttachment(todoId:"a",filename:%q,size:1024,contentType:"application/pdf"){attachmentCount,attachments{id,filename,size,contentType}}}`, name), &data)
+		if data.AddAttachment.AttachmentCount != i+1 {
+			t.Errorf("after %s: attachmentCount = %d, want %d", name, data.AddAttachment.AttachmentCount, i+1)
+		}
+	}
+	attachments := data.AddAttachment.Attachments
+	if len(attachments) != 2 || attachments[0].Filename != "plan.pdf" || attachments[1].Size != 1024 {
+		t.Fatalf("attachments = %+v", attachments)
+	}
+	if !strings.HasPrefix(attachments[0].ID, idPrefixes["attachment"]) || attachments[0].ID == attachments[1].ID {
+		t.Errorf("attachment ids = %q, %q, want distinct prefixed ids", attachments[0].ID, attachments[1].ID)
+	}
+}
+
+func TestAddAttachmentRejected(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "with files"})
+	tests := []struct {
+		name string
+		args string
+		code string
+	}{
+		{"unknown todo", `todoId:"nope",filename:"f",size:1,contentType:"text/plain"`, ErrCodeNotFound},
+		{"empty filename", `todoId:"a",filename:" ",size:1,contentType:"text/plain"`, ErrCodeValidationError},
+		{"negative size", `todoId:"a",filename:"f",size:-1,contentType:"text/plain"`, ErrCodeValidationError},
+		{"too large", fmt.Sprintf(`todoId:"a",filename:"f",size:%d,contentType:"text/plain"`, maxAttachmentSize+1), ErrCodeValidationError},
+		{"bad content type", `todoId:"a",filename:"f",size:1,contentType:"pdf"`, ErrCodeValidationError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := errorCode(t, execute(t, `mutation{addAttachment(`+tt.args+`){id}}`)); code != tt.code {
+				t.Errorf("code = %q, want %s", code, tt.code)
+			}
+		})
+	}
+	if todo, _, _ := store.Get("a"); len(todo.Attachments) != 0 {
+		t.Errorf("attachments = %+v, want none stored", todo.Attachments)
+	}
+
+	// exactly the limit is fine
+	mustExecute(t, fmt.Sprintf(`mutation{addAttachment(todoId:"a",filename:"f",size:%d,contentType:"text/plain; charset=utf-8"){id}}`, maxAttachmentSize), &struct{}{})
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// bearerToken extracts the token from an `Authorization: Bearer <token>` header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// tokenMatches compares tokens in constant time. Both are hashed first so the
+// comparison doesn't leak the length of the expected token either.
+func tokenMatches(got, want string) bool {
+	gotSum := sha256.Sum256([]byte(got))
+	wantSum := sha256.Sum256([]byte(want))
+	return subtle.ConstantTimeCompare(gotSum[:], wantSum[:]) == 1
+}
+
+// Credentials maps the accepted bearer tokens to the identity of their holder.
+// An empty identity accepts the token without identifying the caller.
+type Credentials map[string]string
+
+// named reports whether some token of c identifies its holder.
+func (c Credentials) named() bool {
+	for _, name := range c {
+		if name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCredentials parses a comma separated list of `name:token` pairs.
+func parseCredentials(list string) (Credentials, error) {
+	creds := Credentials{}
+	for _, pair := range strings.Split(list, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, token, ok := strings.Cut(pair, ":")
+		name, token = strings.TrimSpace(name), strings.TrimSpace(token)
+		if !ok || name == "" || token == "" {
+			return nil, fmt.Errorf("invalid credentials %q, want name:token", pair)
+		}
+		creds[token] = name
+	}
+	return creds, nil
+}
+
+// identify returns the identity holding token. Every token is compared so the
+// time taken doesn't tell which one matched.
+func (c Credentials) identify(token string) (identity string, ok bool) {
+	for want, name := range c {
+		if tokenMatches(token, want) {
+			identity, ok = name, true
+		}
+	}
+	return identity, ok
+}
+
+// identityKey is the context key of the authenticated caller's identity.
+type identityKey struct{}
+
+// IdentityFromContext returns the identity BearerAuth authenticated the request
+// as. It reports false when the caller was not identified.
+func IdentityFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	identity, _ := ctx.Value(identityKey{}).(string)
+	return identity, identity != ""
+}
+
+// mayComplete reports whether the caller behind ctx may mark todo done: only
+// its assignee may, unless it is unassigned. Callers BearerAuth left without
+// an identity, the shared token among named ones included, may only complete
+// unassigned todos. Requests that never went through it are not restricted.
+func mayComplete(ctx context.Context, todo Todo) bool {
+	if ctx == nil {
+		return true
+	}
+	identity, checked := ctx.Value(identityKey{}).(string)
+	return !checked || todo.Assignee == "" || todo.Assignee == identity
+}
+
+// BearerAuth requires requests to carry `Authorization: Bearer <token>` with
+// one of the tokens of creds and answers 401 Unauthorized otherwise. The
+// identity of the token is put into the request context, where resolvers find
+// it in params.Context. No credentials disable the check. When some tokens
+// identify their holder, the others are recorded with an empty identity, so
+// mayComplete can tell them from requests without auth.
+func BearerAuth(creds Credentials, next http.Handler) http.Handler {
+	if len(creds) == 0 {
+		return next
+	}
+	named := creds.named()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := bearerToken(r)
+		identity, known := creds.identify(got)
+		if !ok || !known {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="graphql"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		if identity != "" || named {
+			r = r.WithContext(context.WithValue(r.Context(), identityKey{}, identity))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
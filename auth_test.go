@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestBearerAuth(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "secret"})
+	h := BearerAuth(Credentials{"s3cret": ""}, graphqlHandler(t))
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"missing token", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer nope", http.StatusUnauthorized},
+		{"other scheme", "Basic s3cret", http.StatusUnauthorized},
+		{"correct token", "Bearer s3cret", http.StatusOK},
+		{"scheme case", "bearer s3cret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`{todoList{id}}`), nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if leaked := strings.Contains(w.Body.String(), "todoList"); leaked != (tt.status == http.StatusOK) {
+				t.Errorf("body = %s", w.Body)
+			}
+			if tt.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate")
+			}
+		})
+	}
+}
+
+func TestBearerAuthDisabled(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "open"})
+	h := BearerAuth(nil, graphqlHandler(t))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`{todoList{id}}`), nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":"a"`) {
+		t.Errorf("status = %d, body = %s, want the list without a token", w.Code, w.Body)
+	}
+}
+
+func TestBearerAuthIdentity(t *testing.T) {
+	var got string
+	var identified bool
+	h := BearerAuth(Credentials{"alice-token": "alice", "shared": ""}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, identified = IdentityFromContext(r.Context())
+	}))
+	for token, want := range map[string]string{"alice-token": "alice", "shared": ""} {
+		r := httptest.NewRequest(http.MethodGet, "/graphql", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		if got != want || identified != (want != "") {
+			t.Errorf("token %s: identity = %q, %v, want %q", token, got, identified, want)
+		}
+	}
+}
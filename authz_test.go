@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// asIdentity returns a context authenticated as identity, as BearerAuth leaves it.
+func asIdentity(identity string) context.Context {
+	return context.WithValue(context.Background(), identityKey{}, identity)
+}
+
+func TestOnlyAssigneeMarksDone(t *testing.T) {
+	tests := []struct {
+		name     string
+		assignee string
+		ctx      context.Context
+		code     string
+	}{
+		{"assignee", "alice", asIdentity("alice"), ""},
+		{"someone else", "alice", asIdentity("bob"), ErrCodeForbidden},
+		{"unassigned", "", asIdentity("bob"), ""},
+		{"not identified", "alice", context.Background(), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTodos(t, Todo{ID: "a", Text: "assigned", Assignee: tt.assignee})
+			result := executeContext(t, tt.ctx, `mutation{updateTodo(id:"a",done:true){done}}`)
+			code := ""
+			if result.HasErrors() {
+				code = errorCode(t, result)
+			}
+			if code != tt.code {
+				t.Fatalf("code = %q, want %q", code, tt.code)
+			}
+			if todo, _, _ := store.Get("a"); todo.Done != (tt.code == "") {
+				t.Errorf("done = %v", todo.Done)
+			}
+		})
+	}
+}
+
+func TestSharedTokenMarksDone(t *testing.T) {
+	tests := []struct {
+		name     string
+		creds    Credentials
+		assignee string
+		code     string
+	}{
+		{"assigned, next to users", Credentials{"alice-token": "alice", "shared": ""}, "alice", ErrCodeForbidden},
+		{"unassigned, next to users", Credentials{"alice-token": "alice", "shared": ""}, "", ""},
+		{"assigned, shared token only", Credentials{"shared": ""}, "alice", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTodos(t, Todo{ID: "a", Text: "assigned", Assignee: tt.assignee})
+			h := BearerAuth(tt.creds, graphqlHandler(t))
+			r := httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`mutation{updateTodo(id:"a",done:true){done}}`), nil)
+			r.Header.Set("Authorization", "Bearer shared")
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			forbidden := strings.Contains(w.Body.String(), ErrCodeForbidden)
+			if forbidden != (tt.code == ErrCodeForbidden) {
+				t.Fatalf("body = %s, want code %q", w.Body, tt.code)
+			}
+			if todo, _, _ := store.Get("a"); todo.Done != (tt.code == "") {
+				t.Errorf("done = %v", todo.Done)
+			}
+		})
+	}
+}
+
+func TestNonAssigneeMayReopen(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "assigned", Assignee: "alice", Done: true})
+	result := executeContext(t, asIdentity("bob"), `mutation{updateTodo(id:"a",done:false){done}}`)
+	if result.HasErrors() {
+		t.Errorf("marking not done: %v", result.Errors)
+	}
+}
+
+func TestAssigneeCheckOverHTTP(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "assigned", Assignee: "alice"})
+	h := BearerAuth(Credentials{"alice-token": "alice", "bob-token": "bob"}, graphqlHandler(t))
+	update := func(token string) string {
+		r := httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`mutation{updateTodo(id:"a",done:true){done}}`), nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Body.String()
+	}
+	if body := update("bob-token"); !strings.Contains(body, ErrCodeForbidden) {
+		t.Errorf("bob: %s, want FORBIDDEN", body)
+	}
+	if body := update("alice-token"); !strings.Contains(body, `"done":true`) {
+		t.Errorf("alice: %s, want the todo done", body)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "small"})
+	h := MaxBodySize(256, graphqlHandler(t))
+	post := func(body string, knownLength bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		if !knownLength {
+			// as for a chunked request
+			r.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	huge := `{"query":"{todoList{id}}","variables":{"pad":"` + strings.Repeat("x", 1024) + `"}}`
+
+	for _, knownLength := range []bool{true, false} {
+		if w := post(huge, knownLength); w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("oversized body (known length %v): status = %d, want 413", knownLength, w.Code)
+		}
+		w := post(`{"query":"{todoList{id}}"}`, knownLength)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":"a"`) {
+			t.Errorf("normal body (known length %v): status = %d, body = %s", knownLength, w.Code, w.Body)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// dueAt returns a todo due at t.
+func dueAt(t time.Time) Todo {
+	return Todo{ID: NewID("todo"), DueDate: &t}
+}
+
+func TestBusiestUpcomingDay(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	day := func(d, hour int) time.Time { return time.Date(2026, 10, d, hour, 0, 0, 0, time.UTC) }
+	tokyo := time.FixedZone("UTC+9", 9*60*60)
+	tests := []struct {
+		name  string
+		todos []Todo
+		loc   *time.Location
+		want  *DayCount
+	}{
+		{"none due", []Todo{{ID: "a"}}, time.UTC, nil},
+		{"only past", []Todo{dueAt(day(13, 9)), dueAt(day(14, 11))}, time.UTC, nil},
+		{
+			"busiest wins",
+			[]Todo{dueAt(day(15, 9)), dueAt(day(16, 9)), dueAt(day(16, 17)), dueAt(day(10, 9)), dueAt(day(10, 10)), dueAt(day(10, 11))},
+			time.UTC,
+			&DayCount{Day: "2026-10-16", Count: 2},
+		},
+		{"tie goes to the earliest", []Todo{dueAt(day(18, 9)), dueAt(day(17, 9))}, time.UTC, &DayCount{Day: "2026-10-17", Count: 1}},
+		{"later today counts", []Todo{dueAt(day(14, 18))}, time.UTC, &DayCount{Day: "2026-10-14", Count: 1}},
+		{
+			// 20:00 UTC is the next morning in Tokyo
+			"days in loc",
+			[]Todo{dueAt(day(15, 9)), dueAt(day(15, 20)), dueAt(day(16, 1))},
+			tokyo,
+			&DayCount{Day: "2026-10-16", Count: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BusiestUpcomingDay(tt.todos, now, tt.loc)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("BusiestUpcomingDay = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBusiestUpcomingDayQuery(t *testing.T) {
+	resetTodos(t)
+	var data struct{ BusiestUpcomingDay *DayCount }
+	mustExecute(t, `{busiestUpcomingDay{day,count}}`, &data)
+	if data.BusiestUpcomingDay != nil {
+		t.Errorf("busiestUpcomingDay = %+v, want null", data.BusiestUpcomingDay)
+	}
+
+	soon := time.Now().UTC().AddDate(0, 0, 3)
+	resetTodos(t, dueAt(soon), dueAt(soon), dueAt(soon.AddDate(0, 0, 1)))
+	mustExecute(t, `{busiestUpcomingDay{day,count}}`, &data)
+	want := soon.Format("2006-01-02")
+	if data.BusiestUpcomingDay == nil || data.BusiestUpcomingDay.Day != want || data.BusiestUpcomingDay.Count != 2 {
+		t.Errorf("busiestUpcomingDay = %+v, want %s with 2", data.BusiestUpcomingDay, want)
+	}
+
+	if code := errorCode(t, execute(t, `{busiestUpcomingDay(timezone:"Nowhere/Land"){day}}`)); code != ErrCodeValidationError {
+		t.Errorf("code = %q, want %s", code, ErrCodeValidationError)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// publishedIDs returns the ids of the todos published on ch so far.
+func publishedIDs(ch chan interface{}) []string {
+	ids := []string{}
+	for {
+		select {
+		case event := <-ch:
+			ids = append(ids, event.(Todo).ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	then := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	resetTodos(t,
+		Todo{ID: "a", Text: "untagged", CreatedAt: then, UpdatedAt: then, Version: 1},
+		Todo{ID: "b", Text: "tagged", Tags: []string{"urgent"}, CreatedAt: then, UpdatedAt: then, Version: 1},
+		Todo{ID: "c", Text: "left out", CreatedAt: then, UpdatedAt: then, Version: 1},
+	)
+	changes := todoChanges.Subscribe()
+	defer todoChanges.Unsubscribe(changes)
+
+	var data struct{ Classify TodoBatchResult }
+	mustExecute(t, `mutation{classify(ids:["a","b","missing"],tags:["urgent","triage"]){todos{id,tags,version},notFound}}`, &data)
+	if got := todoIDs(data.Classify.Todos); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Fatalf("todos = %v, want a and b", got)
+	}
+	if nf := data.Classify.NotFound; len(nf) != 1 || nf[0] != "missing" {
+		t.Errorf("notFound = %v, want [missing]", nf)
+	}
+	for _, todo := range data.Classify.Todos {
+		if !todo.hasTag("urgent") || !todo.hasTag("triage") || todo.Version != 2 {
+			t.Errorf("todo %s = %+v, want both tags at version 2", todo.ID, todo)
+		}
+	}
+	if b, _, _ := store.Get("b"); len(b.Tags) != 2 {
+		t.Errorf("b tags = %v, want urgent kept once", b.Tags)
+	}
+	if c, _, _ := store.Get("c"); len(c.Tags) != 0 {
+		t.Errorf("c tags = %v, want none", c.Tags)
+	}
+	if ids := publishedIDs(changes); len(ids) != 2 {
+		t.Errorf("published %v, want a and b", ids)
+	}
+}
+
+func TestClassifyUnchanged(t *testing.T) {
+	then := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	resetTodos(t, Todo{ID: "a", Text: "tagged", Tags: []string{"urgent"}, CreatedAt: then, UpdatedAt: then, Version: 1})
+	changes := todoChanges.Subscribe()
+	defer todoChanges.Unsubscribe(changes)
+
+	for _, query := range []string{
+		`mutation{classify(ids:["a"],tags:["urgent"]){todos{id}}}`,
+		`mutation{classify(ids:["a"],tags:[]){todos{id}}}`,
+		`mutation{classify(ids:["a"]){todos{id}}}`,
+	} {
+		var data struct{ Classify TodoBatchResult }
+		mustExecute(t, query, &data)
+		if len(data.Classify.Todos) != 1 {
+			t.Errorf("%s: todos = %v, want a returned", query, data.Classify.Todos)
+		}
+	}
+	a, _, _ := store.Get("a")
+	if a.Version != 1 || !a.UpdatedAt.Equal(then) {
+		t.Errorf("a = %+v, want it untouched", a)
+	}
+	if ids := publishedIDs(changes); len(ids) != 0 {
+		t.Errorf("published %v, want nothing", ids)
+	}
+}
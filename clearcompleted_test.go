@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestClearCompleted(t *testing.T) {
+	resetTodos(t,
+		Todo{ID: "a", Text: "pending"},
+		Todo{ID: "b", Text: "done", Done: true},
+		Todo{ID: "c", Text: "pending too"},
+		Todo{ID: "d", Text: "done too", Done: true},
+	)
+	var data struct{ ClearCompleted []Todo }
+	mustExecute(t, `mutation{clearCompleted{id,done}}`, &data)
+	if ids := todoIDs(data.ClearCompleted); len(ids) != 2 || ids[0] != "a" || ids[1] != "c" {
+		t.Errorf("remaining = %v, want [a c]", ids)
+	}
+	todos, _ := store.List()
+	if ids := todoIDs(todos); len(ids) != 2 || ids[0] != "a" || ids[1] != "c" {
+		t.Errorf("stored = %v, want [a c]", ids)
+	}
+
+	mustExecute(t, `mutation{clearCompleted{id}}`, &data)
+	if len(data.ClearCompleted) != 2 {
+		t.Errorf("second call remaining = %v, want both pending todos", todoIDs(data.ClearCompleted))
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClearTags(t *testing.T) {
+	resetTodos(t,
+		Todo{ID: "a", Text: "one", Tags: []string{"x", "y"}, Version: 1},
+		Todo{ID: "b", Text: "two", Tags: []string{"z"}, Version: 1},
+		Todo{ID: "c", Text: "kept", Tags: []string{"x"}, Version: 1},
+	)
+	var data struct{ ClearTags TodoBatchResult }
+	mustExecute(t, `mutation{clearTags(ids:["a","missing","b"]){todos{id,tags,version},notFound}}`, &data)
+	if ids := todoIDs(data.ClearTags.Todos); len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Fatalf("todos = %v, want [a b]", ids)
+	}
+	for _, todo := range data.ClearTags.Todos {
+		if todo.Tags == nil || len(todo.Tags) != 0 || todo.Version != 2 {
+			t.Errorf("todo %s = %+v, want an empty tag list at version 2", todo.ID, todo)
+		}
+	}
+	if nf := data.ClearTags.NotFound; len(nf) != 1 || nf[0] != "missing" {
+		t.Errorf("notFound = %v, want [missing]", nf)
+	}
+
+	for id, want := range map[string]int{"a": 0, "b": 0, "c": 1} {
+		if todo, _, _ := store.Get(id); len(todo.Tags) != want {
+			t.Errorf("stored %s tags = %v, want %d", id, todo.Tags, want)
+		}
+	}
+}
+
+func TestClearTagsUntagged(t *testing.T) {
+	then := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	resetTodos(t,
+		Todo{ID: "a", Text: "untagged", CreatedAt: then, UpdatedAt: then, Version: 1},
+		Todo{ID: "b", Text: "tagged", Tags: []string{"x"}, CreatedAt: then, UpdatedAt: then, Version: 1},
+	)
+	changes := todoChanges.Subscribe()
+	defer todoChanges.Unsubscribe(changes)
+
+	var data struct{ ClearTags TodoBatchResult }
+	mustExecute(t, `mutation{clearTags(ids:["a","b"]){todos{id,tags,version}}}`, &data)
+	if ids := todoIDs(data.ClearTags.Todos); len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Fatalf("todos = %v, want [a b]", ids)
+	}
+	if a := data.ClearTags.Todos[0]; a.Tags == nil || a.Version != 1 {
+		t.Errorf("a = %+v, want it returned unchanged with an empty tag list", a)
+	}
+	if stored, _, _ := store.Get("a"); stored.Version != 1 || !stored.UpdatedAt.Equal(then) {
+		t.Errorf("stored a = %+v, want it untouched", stored)
+	}
+	if published := publishedIDs(changes); len(published) != 1 || published[0] != "b" {
+		t.Errorf("published = %v, want only [b]", published)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCompleteAllTodos(t *testing.T) {
+	resetTodos(t,
+		Todo{ID: "a", Text: "open"},
+		Todo{ID: "b", Text: "done", Done: true},
+		Todo{ID: "c", Text: "open too"},
+	)
+	for i, want := range []int{2, 0} {
+		var data struct{ CompleteAllTodos int }
+		mustExecute(t, `mutation{completeAllTodos}`, &data)
+		if data.CompleteAllTodos != want {
+			t.Errorf("call %d: changed = %d, want %d", i+1, data.CompleteAllTodos, want)
+		}
+	}
+	todos, _ := store.List()
+	for _, todo := range todos {
+		if !todo.Done {
+			t.Errorf("todo %s not done", todo.ID)
+		}
+	}
+}
+
+func TestCompleteAllTodosSkipsOthersAssigned(t *testing.T) {
+	resetTodos(t,
+		Todo{ID: "a", Text: "mine", Assignee: "alice"},
+		Todo{ID: "b", Text: "bob's", Assignee: "bob"},
+		Todo{ID: "c", Text: "anyone's"},
+	)
+	ctx := context.WithValue(context.Background(), identityKey{}, "alice")
+	var data struct{ CompleteAllTodos int }
+	decodeData(t, executeContext(t, ctx, `mutation{completeAllTodos}`), &data)
+	if data.CompleteAllTodos != 2 {
+		t.Errorf("changed = %d, want 2", data.CompleteAllTodos)
+	}
+	if b, _, _ := store.Get("b"); b.Done {
+		t.Error("bob's todo was completed by alice")
+	}
+}
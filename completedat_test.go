@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// completionState is the part of a todo the completedAt tests look at.
+type completionState struct {
+	ID          string
+	Done        bool
+	CompletedAt *time.Time
+}
+
+func TestCreatedTodosAreNotDone(t *testing.T) {
+	resetTodos(t)
+	var data struct {
+		CreateTodo  completionState
+		CreateTodos []completionState
+	}
+	mustExecute(t, `mutation{
+		createTodo(text:"one",task:"t"){id,done,completedAt}
+		createTodos(inputs:[{text:"two",task:"t"}]){id,done,completedAt}
+	}`, &data)
+	for _, todo := range append(data.CreateTodos, data.CreateTodo) {
+		if todo.Done || todo.CompletedAt != nil {
+			t.Errorf("created todo = %+v, want not done without completedAt", todo)
+		}
+	}
+}
+
+func TestCompletedAtTransitions(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "x", Version: 1})
+	update := func(done bool) completionState {
+		t.Helper()
+		var data struct{ UpdateTodo completionState }
+		query := `mutation{updateTodo(id:"a",done:false){id,done,completedAt}}`
+		if done {
+			query = `mutation{updateTodo(id:"a",done:true){id,done,completedAt}}`
+		}
+		mustExecute(t, query, &data)
+		return data.UpdateTodo
+	}
+
+	before := time.Now().UTC().Add(-time.Second)
+	completed := update(true)
+	if !completed.Done || completed.CompletedAt == nil || completed.CompletedAt.Before(before) {
+		t.Fatalf("false→true: %+v, want completedAt set to now", completed)
+	}
+
+	again := update(true)
+	if again.CompletedAt == nil || !again.CompletedAt.Equal(*completed.CompletedAt) {
+		t.Errorf("true→true: completedAt = %v, want it kept at %v", again.CompletedAt, completed.CompletedAt)
+	}
+
+	if reopened := update(false); reopened.Done || reopened.CompletedAt != nil {
+		t.Errorf("true→false: %+v, want completedAt cleared", reopened)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateTodos(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "existing"})
+	var data struct{ CreateTodos []Todo }
+	mustExecute(t, `mutation{createTodos(inputs:[
+		{text:"one",task:"t"},
+		{text:"two",task:"t",tags:["x"]},
+		{text:"three",task:"t",assignee:"alice"}
+	]){id,text,done,tags,assignee}}`, &data)
+
+	created := data.CreateTodos
+	if len(created) != 3 {
+		t.Fatalf("created = %+v, want 3", created)
+	}
+	seen := map[string]bool{}
+	for i, want := range []string{"one", "two", "three"} {
+		todo := created[i]
+		if todo.Text != want || todo.Done {
+			t.Errorf("created[%d] = %+v, want %q not done", i, todo, want)
+		}
+		if todo.ID == "" || seen[todo.ID] {
+			t.Errorf("created[%d] id = %q, want a new unique id", i, todo.ID)
+		}
+		seen[todo.ID] = true
+	}
+	if len(created[1].Tags) != 1 || created[2].Assignee != "alice" {
+		t.Errorf("created = %+v, want the input fields kept", created)
+	}
+
+	todos, _ := store.List()
+	if texts := todoTexts(todos); texts != "existing one two three" {
+		t.Errorf("stored = %s, want the batch appended in order", texts)
+	}
+}
+
+func TestCreateTodosInvalidInputAbortsBatch(t *testing.T) {
+	resetTodos(t)
+	result := execute(t, `mutation{createTodos(inputs:[{text:"fine",task:"t"},{text:"  ",task:"t"},{text:"also fine",task:"t"}]){id}}`)
+	if code := errorCode(t, result); code != ErrCodeValidationError {
+		t.Errorf("code = %q, want %s", code, ErrCodeValidationError)
+	}
+	if msg := result.Errors[0].Message; !strings.HasPrefix(msg, "inputs[1]: ") {
+		t.Errorf("message = %q, want it to name inputs[1]", msg)
+	}
+	if todos, _ := store.List(); len(todos) != 0 {
+		t.Errorf("stored = %s, want nothing", todoTexts(todos))
+	}
+}
+
+// todoTexts returns the texts of todos joined by spaces.
+func todoTexts(todos []Todo) string {
+	texts := make([]string, len(todos))
+	for i, todo := range todos {
+		texts[i] = todo.Text
+	}
+	return strings.Join(texts, " ")
+}
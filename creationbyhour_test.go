@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCreationByHour(t *testing.T) {
+	at := func(hour, minute int) Todo {
+		return Todo{ID: NewID("todo"), CreatedAt: time.Date(2026, 10, 1, hour, minute, 0, 0, time.UTC)}
+	}
+	resetTodos(t, at(0, 5), at(9, 0), at(9, 59), at(23, 30))
+
+	var data struct{ CreationByHour []HourCount }
+	mustExecute(t, `{creationByHour{hour,count}}`, &data)
+	assertHistogram(t, "UTC", data.CreationByHour, map[int]int{0: 1, 9: 2, 23: 1})
+
+	// Tokyo is UTC+9 all year
+	mustExecute(t, `{creationByHour(timezone:"Asia/Tokyo"){hour,count}}`, &data)
+	assertHistogram(t, "Asia/Tokyo", data.CreationByHour, map[int]int{9: 1, 18: 2, 8: 1})
+}
+
+func TestCreationByHourUnknownTimezone(t *testing.T) {
+	resetTodos(t)
+	if code := errorCode(t, execute(t, `{creationByHour(timezone:"Mars/Olympus"){hour}}`)); code != ErrCodeValidationError {
+		t.Errorf("code = %q, want %s", code, ErrCodeValidationError)
+	}
+}
+
+// assertHistogram checks that histogram has all 24 hours in order, with the
+// counts of want and zero for the others.
+func assertHistogram(t *testing.T, zone string, histogram []HourCount, want map[int]int) {
+	t.Helper()
+	if len(histogram) != 24 {
+		t.Fatalf("%s: got %d hours, want 24", zone, len(histogram))
+	}
+	for hour, bucket := range histogram {
+		if bucket.Hour != hour || bucket.Count != want[hour] {
+			t.Errorf("%s: bucket %d = %+v, want count %d", zone, hour, bucket, want[hour])
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// DeprecationWarning describes one deprecated field selected by a query.
+type DeprecationWarning struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// deprecationUsage collects the deprecated fields resolved during one request.
+type deprecationUsage struct {
+	mu       sync.Mutex
+	seen     map[string]bool
+	warnings []DeprecationWarning
+}
+
+type deprecationUsageKey struct{}
+
+// DeprecationWarnings is a graphql.Extension that lists the deprecated fields a
+// query selected, with their deprecation reasons, under
+// `extensions.deprecations` in the response. The query still runs normally.
+type DeprecationWarnings struct{}
+
+var _ graphql.Extension = DeprecationWarnings{}
+
+func (DeprecationWarnings) Init(ctx context.Context, p *graphql.Params) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, deprecationUsageKey{}, &deprecationUsage{seen: map[string]bool{}})
+}
+
+func (DeprecationWarnings) Name() string {
+	return "deprecations"
+}
+
+func (DeprecationWarnings) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+	return ctx, func(error) {}
+}
+
+func (DeprecationWarnings) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+	return ctx, func([]gqlerrors.FormattedError) {}
+}
+
+func (DeprecationWarnings) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+	return ctx, func(result *graphql.Result) {
+		usage, ok := ctx.Value(deprecationUsageKey{}).(*deprecationUsage)
+		if !ok || result == nil {
+			return
+		}
+		usage.mu.Lock()
+		defer usage.mu.Unlock()
+		if len(usage.warnings) == 0 {
+			return
+		}
+		if result.Extensions == nil {
+			result.Extensions = map[string]interface{}{}
+		}
+		result.Extensions["deprecations"] = usage.warnings
+	}
+}
+
+func (DeprecationWarnings) ResolveFieldDidStart(ctx context.Context, info *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+	noop := func(interface{}, error) {}
+	usage, ok := ctx.Value(deprecationUsageKey{}).(*deprecationUsage)
+	if !ok {
+		return ctx, noop
+	}
+	object, ok := info.ParentType.(*graphql.Object)
+	if !ok {
+		return ctx, noop
+	}
+	field, ok := object.Fields()[info.FieldName]
+	if !ok || field.DeprecationReason == "" {
+		return ctx, noop
+	}
+
+	name := object.Name() + "." + info.FieldName
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+	if !usage.seen[name] {
+		usage.seen[name] = true
+		usage.warnings = append(usage.warnings, DeprecationWarning{Field: name, Reason: field.DeprecationReason})
+	}
+	return ctx, noop
+}
+
+// HasResult is false: warnings are attached in ExecutionDidStart's finish
+// function so that queries without deprecated fields get no extensions entry.
+func (DeprecationWarnings) HasResult() bool {
+	return false
+}
+
+func (DeprecationWarnings) GetResult(context.Context) interface{} {
+	return nil
+}
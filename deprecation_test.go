@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+// executeWithDeprecations runs query on a schema with DeprecationWarnings
+// installed and returns the result's deprecation warnings.
+func executeWithDeprecations(t *testing.T, query string) []DeprecationWarning {
+	t.Helper()
+	schema, err := BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema.AddExtensions(DeprecationWarnings{})
+	result := graphql.Do(graphql.Params{Schema: schema, RequestString: query, Context: context.Background()})
+	if result.HasErrors() {
+		t.Fatalf("%s: %v", query, result.Errors)
+	}
+	if _, ok := result.Extensions["deprecations"]; !ok {
+		return nil
+	}
+	data, err := json.Marshal(result.Extensions["deprecations"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var warnings []DeprecationWarning
+	if err := json.Unmarshal(data, &warnings); err != nil {
+		t.Fatal(err)
+	}
+	return warnings
+}
+
+func TestDeprecationWarnings(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "x", Task: "t"}, Todo{ID: "b", Text: "y", Task: "t"})
+
+	warnings := executeWithDeprecations(t, `{todoList{id,task}}`)
+	if len(warnings) != 1 {
+		t.Fatalf("warnings = %+v, want one for Todo.task, however many todos select it", warnings)
+	}
+	if warnings[0].Field != "Todo.task" || warnings[0].Reason == "" {
+		t.Errorf("warning = %+v, want Todo.task with a reason", warnings[0])
+	}
+
+	if warnings := executeWithDeprecations(t, `{todoList{id,text}}`); warnings != nil {
+		t.Errorf("warnings = %+v for a query without deprecated fields, want none", warnings)
+	}
+}
+
+func TestDeprecationWarningsDisabled(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "x", Task: "t"})
+	result := execute(t, `{todoList{task}}`)
+	if _, ok := result.Extensions["deprecations"]; ok {
+		t.Errorf("extensions = %v without the extension installed", result.Extensions)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/handler"
+)
+
+// QueryDepth returns the deepest field nesting of any operation in doc.
+// Fragment spreads count as the fields they expand to. Introspection fields
+// count like any other, only `__typename` is free as it never nests.
+func QueryDepth(doc *ast.Document) int {
+	walker := depthWalker{
+		fragments: map[string]*ast.FragmentDefinition{},
+		depths:    map[string]int{},
+		visiting:  map[string]bool{},
+	}
+	for _, def := range doc.Definitions {
+		if fragment, ok := def.(*ast.FragmentDefinition); ok {
+			walker.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	depth := 0
+	for _, def := range doc.Definitions {
+		if operation, ok := def.(*ast.OperationDefinition); ok {
+			if d := walker.selectionDepth(operation.SelectionSet); d > depth {
+				depth = d
+			}
+		}
+	}
+	return depth
+}
+
+// depthWalker measures selection sets of one document.
+type depthWalker struct {
+	fragments map[string]*ast.FragmentDefinition
+	// depths caches the depth of each fragment, so a fragment spread many
+	// times is only walked once instead of once per spread.
+	depths map[string]int
+	// visiting holds the fragments being expanded, so a fragment cycle stops
+	// instead of recursing forever; validation reports the cycle later.
+	visiting map[string]bool
+}
+
+// selectionDepth returns the depth of set.
+func (w *depthWalker) selectionDepth(set *ast.SelectionSet) int {
+	if set == nil {
+		return 0
+	}
+	depth := 0
+	for _, selection := range set.Selections {
+		d := 0
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if selection.Name.Value == "__typename" {
+				continue
+			}
+			d = 1 + w.selectionDepth(selection.SelectionSet)
+		case *ast.InlineFragment:
+			d = w.selectionDepth(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			d = w.fragmentDepth(selection.Name.Value)
+		}
+		if d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
+// fragmentDepth returns the depth of the fragment called name, 0 for unknown
+// fragments and for spreads that close a cycle.
+func (w *depthWalker) fragmentDepth(name string) int {
+	if d, ok := w.depths[name]; ok {
+		return d
+	}
+	fragment, ok := w.fragments[name]
+	if !ok || w.visiting[name] {
+		return 0
+	}
+	w.visiting[name] = true
+	d := w.selectionDepth(fragment.SelectionSet)
+	delete(w.visiting, name)
+	w.depths[name] = d
+	return d
+}
+
+// peekRequestOptions reads the query, variables and operation name of a
+// GraphQL request the way the handler will, leaving r's body for the handler.
+func peekRequestOptions(r *http.Request) (*handler.RequestOptions, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	// NewRequestOptions consumes the body, give it a copy and keep one for the handler
+	peek := r.Clone(r.Context())
+	peek.Body = io.NopCloser(bytes.NewReader(body))
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return handler.NewRequestOptions(peek), nil
+}
+
+// parsedRequestKey is the context key of the parsedRequest of a request.
+type parsedRequestKey struct{}
+
+// parsedRequest is a GraphQL request as the handler will see it, parsed once
+// for all the middlewares that look at its query.
+type parsedRequest struct {
+	Options *handler.RequestOptions
+	// Document is nil when there is no query or it doesn't parse.
+	Document *ast.Document
+}
+
+// parseRequest returns the parsed GraphQL request of r, parsing it only when
+// an outer middleware has not done so yet. The returned request carries the
+// result in its context for the middlewares and handler further in.
+func parseRequest(r *http.Request) (*http.Request, *parsedRequest, error) {
+	if parsed, ok := r.Context().Value(parsedRequestKey{}).(*parsedRequest); ok {
+		return r, parsed, nil
+	}
+	opts, err := peekRequestOptions(r)
+	if err != nil {
+		return r, nil, err
+	}
+	parsed := &parsedRequest{Options: opts}
+	if opts.Query != "" {
+		if doc, err := parser.Parse(parser.ParseParams{Source: opts.Query}); err == nil {
+			parsed.Document = doc
+		}
+	}
+	return r.WithContext(context.WithValue(r.Context(), parsedRequestKey{}, parsed)), parsed, nil
+}
+
+// MaxQueryDepth rejects requests whose query nests fields deeper than limit,
+// before they reach the GraphQL handler. Queries that don't parse are passed
+// on so the handler can report the syntax error.
+func MaxQueryDepth(limit int, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, parsed, err := parseRequest(r)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		if parsed.Document != nil {
+			if depth := QueryDepth(parsed.Document); depth > limit {
+				writeGraphQLError(w, http.StatusBadRequest, fmt.Sprintf("query depth %d exceeds the maximum of %d", depth, limit))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/testutil"
+)
+
+func TestMaxQueryDepth(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := MaxQueryDepth(3, ok)
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"shallow", `{todoList{id,attachments{id}}}`, http.StatusOK},
+		{"too deep", `{todoList{attachments{id{a{b}}}}}`, http.StatusBadRequest},
+		{"deep through fragment", `{todoList{...F}} fragment F on Todo {attachments{id{a}}}`, http.StatusBadRequest},
+		{"typename is free", `{todoList{attachments{id __typename}}}`, http.StatusOK},
+		{"introspection", `{__schema{types{fields{type{ofType{name}}}}}}`, http.StatusBadRequest},
+		{"deep introspection", `{__schema{types{fields{type{fields{type{fields{type{fields{type{fields{type{name}}}}}}}}}}}}}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(tt.query), nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status == http.StatusBadRequest && !strings.Contains(w.Body.String(), "exceeds the maximum of 3") {
+				t.Errorf("body = %s, want the depth limit in the message", w.Body)
+			}
+		})
+	}
+}
+
+func TestQueryDepthFragmentFanOut(t *testing.T) {
+	// every fragment spreads the next one twice, 2^40 expansions if walked naively
+	var query strings.Builder
+	query.WriteString("{todoList{...F0}}\n")
+	const n = 40
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&query, "fragment F%d on Todo { ...F%d ...F%d }\n", i, i+1, i+1)
+	}
+	fmt.Fprintf(&query, "fragment F%d on Todo { attachments { id } }\n", n)
+	doc, err := parser.Parse(parser.ParseParams{Source: query.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan int, 1)
+	go func() { done <- QueryDepth(doc) }()
+	select {
+	case depth := <-done:
+		if depth != 3 {
+			t.Errorf("QueryDepth = %d, want 3", depth)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("QueryDepth did not finish, fragments are expanded once per spread")
+	}
+}
+
+func TestQueryDepthFragmentCycle(t *testing.T) {
+	doc, err := parser.Parse(parser.ParseParams{Source: `{todoList{...A}} fragment A on Todo {id ...B} fragment B on Todo {...A}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if depth := QueryDepth(doc); depth != 2 {
+		t.Errorf("QueryDepth = %d, want 2", depth)
+	}
+}
+
+func TestQueryDepthIntrospection(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		depth int
+	}{
+		{"print-schema", introspectionQuery, 10},
+		{"graphiql", testutil.IntrospectionQuery, 13},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if depth := QueryDepth(doc); depth != tt.depth {
+				t.Errorf("QueryDepth = %d, want %d", depth, tt.depth)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// completion records a todo at the moment it was marked done.
+type completion struct {
+	Todo Todo
+	At   time.Time
+}
+
+// CompletionDigest collects completed todos between runs so they can be
+// reported as a single digest instead of one notification per completion.
+type CompletionDigest struct {
+	mu        sync.Mutex
+	lastRun   time.Time
+	completed []completion
+}
+
+// NewCompletionDigest returns a digest whose first run covers everything completed after now.
+func NewCompletionDigest(now time.Time) *CompletionDigest {
+	return &CompletionDigest{lastRun: now}
+}
+
+// Record notes that todo was completed at the given time. Recording into a nil
+// digest does nothing, so nothing piles up while the digest is disabled.
+func (d *CompletionDigest) Record(todo Todo, at time.Time) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.completed = append(d.completed, completion{Todo: todo, At: at})
+}
+
+// Run returns the todos completed since the previous run, emits them as one
+// digest entry in the log and moves the last-run timestamp to now.
+// Nothing is logged when no todo was completed.
+func (d *CompletionDigest) Run(now time.Time) []Todo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	since := d.lastRun
+	todos := []Todo{}
+	pending := d.completed[:0]
+	for _, c := range d.completed {
+		switch {
+		case c.At.After(now):
+			// completed after this run's cut-off, keep it for the next one
+			pending = append(pending, c)
+		case c.At.After(since):
+			todos = append(todos, c.Todo)
+		}
+	}
+	d.completed = pending
+	d.lastRun = now
+
+	if len(todos) > 0 {
+		ids := make([]string, len(todos))
+		for i, todo := range todos {
+			ids[i] = todo.ID
+		}
+		log.Printf("completion digest: %d todo(s) completed since %s: %v", len(todos), since.Format(time.RFC3339), ids)
+	}
+	return todos
+}
+
+// Start runs the digest every interval until the process exits.
+func (d *CompletionDigest) Start(interval time.Duration) {
+	go func() {
+		for now := range time.Tick(interval) {
+			d.Run(now)
+		}
+	}()
+}
+
+// completionDigest is fed by the mutations that mark todos done. It is nil
+// unless -digest-interval starts a digest.
+var completionDigest *CompletionDigest
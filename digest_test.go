@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompletionDigestRun(t *testing.T) {
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	d := NewCompletionDigest(start)
+	d.Record(Todo{ID: "a"}, start.Add(time.Minute))
+	d.Record(Todo{ID: "b"}, start.Add(2*time.Minute))
+	// completed after the cut-off of the first run
+	d.Record(Todo{ID: "c"}, start.Add(time.Hour))
+
+	if got := todoIDs(d.Run(start.Add(30 * time.Minute))); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("first run = %v, want [a b]", got)
+	}
+	if got := todoIDs(d.Run(start.Add(2 * time.Hour))); !reflect.DeepEqual(got, []string{"c"}) {
+		t.Errorf("second run = %v, want [c]", got)
+	}
+	if got := d.Run(start.Add(3 * time.Hour)); len(got) != 0 {
+		t.Errorf("third run = %v, want nothing", todoIDs(got))
+	}
+}
+
+func TestCompletionDigestDisabled(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "x", Version: 1})
+	if completionDigest != nil {
+		t.Fatal("completionDigest should be nil unless -digest-interval is set")
+	}
+	// mutations record into the nil digest without panicking
+	mustExecute(t, `mutation{updateTodo(id:"a",done:true){id}}`, &struct{}{})
+}
+
+func TestCompletionDigestFedByMutations(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "x", Version: 1}, Todo{ID: "b", Text: "y", Version: 1})
+	completionDigest = NewCompletionDigest(time.Now().Add(-time.Minute))
+	t.Cleanup(func() { completionDigest = nil })
+
+	mustExecute(t, `mutation{updateTodo(id:"a",done:true){id}}`, &struct{}{})
+	if got := todoIDs(completionDigest.Run(time.Now().Add(time.Minute))); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("digest = %v, want [a]", got)
+	}
+}
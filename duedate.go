@@ -0,0 +1,62 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Due date parsing settings, set from flags in main.
+var (
+	// parseDueDates makes createTodo take the due date from a date phrase in
+	// the text when no dueDate argument is given.
+	parseDueDates bool
+	// stripDuePhrases removes the recognised phrase from the todo text.
+	stripDuePhrases bool
+)
+
+// duePhrase matches the small set of date phrases ParseDueDate understands.
+var duePhrase = regexp.MustCompile(`(?i)\b(today|tomorrow|next (week|monday|tuesday|wednesday|thursday|friday|saturday|sunday))\b`)
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// ParseDueDate looks for a date phrase such as "tomorrow" or "next Friday" in
+// text and returns the day it refers to (midnight in now's location), the text
+// with the phrase removed, and whether a phrase was found. "next <weekday>"
+// is the first such day after today, "next week" is seven days from today.
+func ParseDueDate(text string, now time.Time) (time.Time, string, bool) {
+	match := duePhrase.FindStringSubmatchIndex(text)
+	if match == nil {
+		return time.Time{}, text, false
+	}
+	phrase := strings.ToLower(text[match[0]:match[1]])
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var due time.Time
+	switch phrase {
+	case "today":
+		due = today
+	case "tomorrow":
+		due = today.AddDate(0, 0, 1)
+	case "next week":
+		due = today.AddDate(0, 0, 7)
+	default:
+		weekday := weekdays[strings.TrimPrefix(phrase, "next ")]
+		days := (int(weekday) - int(today.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		due = today.AddDate(0, 0, days)
+	}
+
+	stripped := strings.Join(strings.Fields(text[:match[0]]+" "+text[match[1]:]), " ")
+	return due, stripped, true
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDueDate(t *testing.T) {
+	// a Wednesday afternoon
+	now := time.Date(2026, 10, 14, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		text     string
+		due      string
+		stripped string
+	}{
+		{"call mom tomorrow", "2026-10-15", "call mom"},
+		{"Tomorrow: water plants", "2026-10-15", ": water plants"},
+		{"pay rent today", "2026-10-14", "pay rent"},
+		{"review next Friday please", "2026-10-16", "review please"},
+		{"standup next wednesday", "2026-10-21", "standup"},
+		{"plan next week", "2026-10-21", "plan"},
+	}
+	for _, tt := range tests {
+		due, stripped, ok := ParseDueDate(tt.text, now)
+		if !ok || due.Format("2006-01-02") != tt.due || stripped != tt.stripped {
+			t.Errorf("ParseDueDate(%q) = %v, %q, %v, want %s, %q", tt.text, due, stripped, ok, tt.due, tt.stripped)
+		}
+		if ok && (due.Hour() != 0 || due.Location() != now.Location()) {
+			t.Errorf("ParseDueDate(%q) = %v, want midnight in now's location", tt.text, due)
+		}
+	}
+
+	for _, text := range []string{"buy milk", "tomorrowland tickets", "next time"} {
+		if _, stripped, ok := ParseDueDate(text, now); ok || stripped != text {
+			t.Errorf("ParseDueDate(%q) found a date", text)
+		}
+	}
+}
+
+// setDueDateParsing sets the due date parsing flags for the rest of the test.
+func setDueDateParsing(t *testing.T, parse, strip bool) {
+	t.Helper()
+	parseDueDates, stripDuePhrases = parse, strip
+	t.Cleanup(func() { parseDueDates, stripDuePhrases = false, false })
+}
+
+func TestCreateTodoParsesDueDate(t *testing.T) {
+	resetTodos(t)
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	var data struct{ CreateTodo Todo }
+
+	setDueDateParsing(t, false, false)
+	mustExecute(t, `mutation{createTodo(text:"call mom tomorrow",task:"t"){text,dueDate}}`, &data)
+	if data.CreateTodo.DueDate != nil {
+		t.Errorf("parsing off: dueDate = %v, want none", data.CreateTodo.DueDate)
+	}
+
+	setDueDateParsing(t, true, false)
+	mustExecute(t, `mutation{createTodo(text:"call mom tomorrow",task:"t"){text,dueDate}}`, &data)
+	if data.CreateTodo.DueDate == nil || data.CreateTodo.DueDate.Format("2006-01-02") != tomorrow || data.CreateTodo.Text != "call mom tomorrow" {
+		t.Errorf("todo = %+v, want due %s with the text intact", data.CreateTodo, tomorrow)
+	}
+
+	mustExecute(t, `mutation{createTodo(text:"buy milk",task:"t"){text,dueDate}}`, &data)
+	if data.CreateTodo.DueDate != nil {
+		t.Errorf("no phrase: dueDate = %v, want none", data.CreateTodo.DueDate)
+	}
+
+	mustExecute(t, `mutation{createTodo(text:"call mom tomorrow",task:"t",dueDate:"2027-01-01T00:00:00Z"){dueDate}}`, &data)
+	if data.CreateTodo.DueDate == nil || data.CreateTodo.DueDate.Year() != 2027 {
+		t.Errorf("dueDate = %v, want the given one to win", data.CreateTodo.DueDate)
+	}
+
+	setDueDateParsing(t, true, true)
+	mustExecute(t, `mutation{createTodo(text:"call mom tomorrow",task:"t"){text,dueDate}}`, &data)
+	if data.CreateTodo.Text != "call mom" || data.CreateTodo.DueDate == nil {
+		t.Errorf("todo = %+v, want the phrase stripped", data.CreateTodo)
+	}
+}
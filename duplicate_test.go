@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuplicateTodo(t *testing.T) {
+	created := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	original := Todo{
+		ID:          "a",
+		Text:        "write report",
+		Done:        true,
+		CompletedAt: &created,
+		Tags:        []string{"work"},
+		CreatedAt:   created,
+		UpdatedAt:   created,
+		Version:     4,
+		Archived:    true,
+		ExternalRef: "https://github.com/o/r/issues/1",
+		Attachments: []Attachment{{ID: "attachment_1", Filename: "plan.pdf", Size: 10, ContentType: "application/pdf", AddedAt: created}},
+	}
+	resetTodos(t, original)
+
+	var data struct{ DuplicateTodo Todo }
+	mustExecute(t, `mutation{duplicateTodo(id:"a"){id,text,done,completedAt,tags,version,archived,externalRef,attachments{id,filename}}}`, &data)
+	clone := data.DuplicateTodo
+	if clone.ID == "" || sameTodoID(clone.ID, "a") {
+		t.Errorf("id = %q, want a new id", clone.ID)
+	}
+	if clone.Text != "write report" || len(clone.Tags) != 1 || clone.Tags[0] != "work" {
+		t.Errorf("clone = %+v, want the text and tags copied", clone)
+	}
+	if clone.Done || clone.CompletedAt != nil || clone.Version != 1 {
+		t.Errorf("clone = %+v, want a new not done todo", clone)
+	}
+	if clone.Archived || clone.ExternalRef != "" {
+		t.Errorf("archived = %v, externalRef = %q, want both reset", clone.Archived, clone.ExternalRef)
+	}
+	if len(clone.Attachments) != 1 || clone.Attachments[0].Filename != "plan.pdf" {
+		t.Fatalf("attachments = %+v, want plan.pdf copied", clone.Attachments)
+	}
+	if clone.Attachments[0].ID == "" || clone.Attachments[0].ID == "attachment_1" {
+		t.Errorf("attachment id = %q, want a new id", clone.Attachments[0].ID)
+	}
+
+	stored, ok, err := store.Get("a")
+	if err != nil || !ok {
+		t.Fatalf("get a: %v, %v", ok, err)
+	}
+	if !stored.Archived || stored.ExternalRef == "" || stored.Attachments[0].ID != "attachment_1" || stored.Version != 4 {
+		t.Errorf("original = %+v, want it unchanged", stored)
+	}
+}
+
+func TestDuplicateTodoNotFound(t *testing.T) {
+	resetTodos(t)
+	if code := errorCode(t, execute(t, `mutation{duplicateTodo(id:"nope"){id}}`)); code != ErrCodeNotFound {
+		t.Errorf("code = %q, want %s", code, ErrCodeNotFound)
+	}
+}
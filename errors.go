@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Error codes reported under `extensions.code` in GraphQL error responses,
+// so clients can branch on the kind of failure instead of parsing messages.
+const (
+	ErrCodeNotFound        = "NOT_FOUND"
+	ErrCodeValidationError = "VALIDATION_ERROR"
+	ErrCodeConflict        = "CONFLICT"
+	ErrCodeForbidden       = "FORBIDDEN"
+	ErrCodeInternal        = "INTERNAL_ERROR"
+)
+
+// CodedError is a resolver error carrying a stable code.
+// It implements gqlerrors.ExtendedError, so graphql-go copies the code into the
+// `extensions` of the formatted error.
+type CodedError struct {
+	Code    string
+	Message string
+}
+
+func (e *CodedError) Error() string {
+	return e.Message
+}
+
+// Extensions returns the GraphQL error extensions for e.
+func (e *CodedError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+// NewCodedError builds a CodedError with a formatted message.
+func NewCodedError(code, format string, args ...interface{}) *CodedError {
+	return &CodedError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// todoNotFound is returned by resolvers when no todo matches the requested id.
+func todoNotFound(id string) *CodedError {
+	return NewCodedError(ErrCodeNotFound, "todo %q not found", id)
+}
+
+// storeError logs a failure of the todo store and returns a generic GraphQL
+// error, so database details are not sent to the client. A taken todo id is
+// reported as a CONFLICT instead.
+func storeError(err error) *CodedError {
+	if errors.Is(err, ErrDuplicateID) {
+		return NewCodedError(ErrCodeConflict, "%v", err)
+	}
+	log.Printf("todo store: %v", err)
+	return NewCodedError(ErrCodeInternal, "internal error")
+}
+
+// safeResolve wraps a resolver so a panic inside it becomes a generic GraphQL
+// error instead of escaping. The panic value and stack are only logged, never
+// sent to the client.
+func safeResolve(resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(params graphql.ResolveParams) (result interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic resolving %s: %v\n%s", params.Info.FieldName, r, debug.Stack())
+				result, err = nil, NewCodedError(ErrCodeInternal, "internal error")
+			}
+		}()
+		return resolve(params)
+	}
+}
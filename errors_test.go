@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestErrorCodes(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "exists", Version: 1})
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{"update unknown id", `mutation{updateTodo(id:"nope",done:true){id}}`, ErrCodeNotFound},
+		{"empty text", `mutation{createTodo(text:"  ",task:"t"){id}}`, ErrCodeValidationError},
+		{"stale version", `mutation{updateTodo(id:"a",done:true,expectedVersion:7){id}}`, ErrCodeConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := errorCode(t, execute(t, tt.query)); code != tt.code {
+				t.Errorf("code = %q, want %s", code, tt.code)
+			}
+		})
+	}
+}
+
+func TestErrorCodeInResponseJSON(t *testing.T) {
+	resetTodos(t)
+	body, err := json.Marshal(execute(t, `mutation{updateTodo(id:"nope",done:true){id}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), `"extensions":{"code":"NOT_FOUND"}`) {
+		t.Errorf("response = %s, want extensions.code", body)
+	}
+	if !strings.Contains(string(body), `todo \"nope\" not found`) {
+		t.Errorf("response = %s, want the message", body)
+	}
+}
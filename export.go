@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Export formats accepted by ExportTodos.
+const (
+	ExportJSON  = "JSON"
+	ExportJSONL = "JSONL"
+	ExportCSV   = "CSV"
+	ExportICS   = "ICS"
+)
+
+// ExportTodos renders todos in the given format. The JSON format is what
+// ImportTodos reads, so an export can be imported again as a backup.
+func ExportTodos(todos []Todo, format string) (string, error) {
+	switch format {
+	case ExportJSON:
+		if todos == nil {
+			// an empty store exports as an empty array rather than null
+			todos = []Todo{}
+		}
+		data, err := json.Marshal(todos)
+		return string(data), err
+	case ExportJSONL:
+		return exportJSONL(todos)
+	case ExportCSV:
+		return exportCSV(todos)
+	case ExportICS:
+		return exportICS(todos), nil
+	}
+	return "", fmt.Errorf("unknown export format %q", format)
+}
+
+// exportJSONL writes one JSON encoded todo per line.
+func exportJSONL(todos []Todo) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, todo := range todos {
+		if err := encoder.Encode(todo); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
+
+// exportCSV writes a header row followed by one row per todo; tags are joined with ";".
+func exportCSV(todos []Todo) (string, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"id", "text", "done", "task", "tags", "assignee", "updatedAt"})
+	for _, todo := range todos {
+		writer.Write([]string{
+			todo.ID,
+			todo.Text,
+			strconv.FormatBool(todo.Done),
+			todo.Task,
+			strings.Join(todo.Tags, ";"),
+			todo.Assignee,
+			todo.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	writer.Flush()
+	return buf.String(), writer.Error()
+}
+
+// icsTimeFormat is the UTC DATE-TIME form of RFC 5545.
+const icsTimeFormat = "20060102T150405Z"
+
+// icsEscaper escapes TEXT values as required by RFC 5545.
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// exportICS writes an iCalendar document with one VTODO per todo.
+func exportICS(todos []Todo) string {
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//GoGraphQL//Todos//EN"}
+	for _, todo := range todos {
+		status := "NEEDS-ACTION"
+		if todo.Done {
+			status = "COMPLETED"
+		}
+		lines = append(lines,
+			"BEGIN:VTODO",
+			"UID:"+icsEscaper.Replace(todo.ID),
+			"DTSTAMP:"+todo.UpdatedAt.UTC().Format(icsTimeFormat),
+			"SUMMARY:"+icsEscaper.Replace(todo.Text),
+			"STATUS:"+status,
+		)
+		if todo.DueDate != nil {
+			lines = append(lines, "DUE:"+todo.DueDate.UTC().Format(icsTimeFormat))
+		}
+		if todo.CompletedAt != nil {
+			lines = append(lines, "COMPLETED:"+todo.CompletedAt.UTC().Format(icsTimeFormat))
+		}
+		if todo.Task != "" {
+			lines = append(lines, "DESCRIPTION:"+icsEscaper.Replace(todo.Task))
+		}
+		if len(todo.Tags) > 0 {
+			categories := make([]string, len(todo.Tags))
+			for i, tag := range todo.Tags {
+				categories[i] = icsEscaper.Replace(tag)
+			}
+			lines = append(lines, "CATEGORIES:"+strings.Join(categories, ","))
+		}
+		lines = append(lines, "END:VTODO")
+	}
+	lines = append(lines, "END:VCALENDAR")
+	for i, line := range lines {
+		lines[i] = foldICSLine(line)
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// icsMaxLine is the longest content line RFC 5545 allows, in octets.
+const icsMaxLine = 75
+
+// foldICSLine splits line into lines of at most icsMaxLine octets, each
+// continuation starting with a space as RFC 5545 requires. Lines are only
+// broken between UTF-8 sequences, never inside one.
+func foldICSLine(line string) string {
+	if len(line) <= icsMaxLine {
+		return line
+	}
+	var folded strings.Builder
+	width := 0
+	for len(line) > 0 {
+		_, size := utf8.DecodeRuneInString(line)
+		if width+size > icsMaxLine {
+			folded.WriteString("\r\n ")
+			// the leading space counts towards the continuation line
+			width = 1
+		}
+		folded.WriteString(line[:size])
+		width += size
+		line = line[size:]
+	}
+	return folded.String()
+}
+
+// ImportTodos decodes a JSON array of todos, as written by ExportTodos in the
+// JSON format. Entries without an id get a fresh one, missing creation and
+// update times are set to now and a missing version to 1. An id given to more
+// than one entry is an error.
+func ImportTodos(data []byte, now time.Time) ([]Todo, error) {
+	var todos []Todo
+	if err := json.Unmarshal(data, &todos); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for i := range todos {
+		if todos[i].ID == "" {
+			todos[i].ID = NewID("todo")
+		}
+		key := StripIDPrefix("todo", todos[i].ID)
+		if seen[key] {
+			return nil, fmt.Errorf("id %q is used by more than one todo", todos[i].ID)
+		}
+		seen[key] = true
+		if todos[i].CreatedAt.IsZero() {
+			todos[i].CreatedAt = now
+		}
+		if todos[i].UpdatedAt.IsZero() {
+			todos[i].UpdatedAt = now
+		}
+		if todos[i].Version == 0 {
+			todos[i].Version = 1
+		}
+	}
+	return todos, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// exportFixture is a list whose text needs escaping in every format.
+func exportFixture() []Todo {
+	updated := time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC)
+	return []Todo{
+		{ID: "a", Text: `say "hi", then; leave`, Task: "chat", Tags: []string{"x", "y,z"}, UpdatedAt: updated, Version: 1},
+		{ID: "b", Text: "line one\nline two", Done: true, UpdatedAt: updated, Version: 2},
+	}
+}
+
+// exportAs runs the exportTodos query in format, or the default when it is empty.
+func exportAs(t *testing.T, format string) string {
+	t.Helper()
+	query := `{exportTodos}`
+	if format != "" {
+		query = `{exportTodos(format:` + format + `)}`
+	}
+	var data struct{ ExportTodos string }
+	mustExecute(t, query, &data)
+	return data.ExportTodos
+}
+
+func TestExportFormats(t *testing.T) {
+	resetTodos(t, exportFixture()...)
+
+	t.Run("JSON", func(t *testing.T) {
+		for _, format := range []string{"", ExportJSON} {
+			var todos []Todo
+			if err := json.Unmarshal([]byte(exportAs(t, format)), &todos); err != nil {
+				t.Fatalf("format %q: %v", format, err)
+			}
+			if len(todos) != 2 || todos[0].Text != exportFixture()[0].Text {
+				t.Errorf("format %q: todos = %+v", format, todos)
+			}
+		}
+	})
+
+	t.Run("JSONL", func(t *testing.T) {
+		scanner := bufio.NewScanner(strings.NewReader(exportAs(t, ExportJSONL)))
+		n := 0
+		for scanner.Scan() {
+			var todo Todo
+			if err := json.Unmarshal(scanner.Bytes(), &todo); err != nil {
+				t.Fatalf("line %d: %v", n+1, err)
+			}
+			n++
+		}
+		if n != 2 {
+			t.Errorf("got %d lines, want 2", n)
+		}
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		records, err := csv.NewReader(strings.NewReader(exportAs(t, ExportCSV))).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 3 || records[0][0] != "id" {
+			t.Fatalf("records = %q, want a header and 2 rows", records)
+		}
+		if records[1][1] != exportFixture()[0].Text || records[1][4] != "x;y,z" || records[2][2] != "true" {
+			t.Errorf("rows = %q", records[1:])
+		}
+	})
+
+	t.Run("ICS", func(t *testing.T) {
+		ics := exportAs(t, ExportICS)
+		if !strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(ics, "END:VCALENDAR\r\n") {
+			t.Fatalf("ics = %q, want a calendar", ics)
+		}
+		for _, line := range []string{
+			`SUMMARY:say "hi"\, then\; leave`,
+			`SUMMARY:line one\nline two`,
+			`CATEGORIES:x,y\,z`,
+			"STATUS:COMPLETED",
+			"DTSTAMP:20261001T083000Z",
+		} {
+			if !strings.Contains(ics, line+"\r\n") {
+				t.Errorf("ics has no line %q", line)
+			}
+		}
+		if strings.Count(ics, "BEGIN:VTODO") != 2 || strings.Count(ics, "END:VTODO") != 2 {
+			t.Errorf("ics = %q, want 2 VTODOs", ics)
+		}
+	})
+}
+
+func TestExportInvalidFormat(t *testing.T) {
+	resetTodos(t)
+	if result := execute(t, `{exportTodos(format:XML)}`); !result.HasErrors() {
+		t.Error("exportTodos accepted format XML")
+	}
+	if _, err := ExportTodos(nil, "XML"); err == nil {
+		t.Error("ExportTodos accepted format XML")
+	}
+	if got := exportAs(t, ExportJSON); got != "[]" {
+		t.Errorf("empty export = %q, want []", got)
+	}
+}
+
+func TestExportICSDates(t *testing.T) {
+	due := time.Date(2026, 10, 20, 17, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	completed := time.Date(2026, 10, 2, 9, 15, 30, 0, time.UTC)
+	ics := exportICS([]Todo{
+		{ID: "a", Text: "due", DueDate: &due},
+		{ID: "b", Text: "done", Done: true, CompletedAt: &completed},
+	})
+	for _, line := range []string{"DUE:20261020T150000Z", "COMPLETED:20261002T091530Z"} {
+		if !strings.Contains(ics, "\r\n"+line+"\r\n") {
+			t.Errorf("ics has no line %q: %q", line, ics)
+		}
+	}
+	if strings.Count(ics, "DUE:") != 1 || strings.Count(ics, "COMPLETED:") != 1 {
+		t.Errorf("ics = %q, want DUE and COMPLETED only where set", ics)
+	}
+}
+
+func TestExportICSFolding(t *testing.T) {
+	text := strings.Repeat("abcdéfgh ", 30)
+	ics := exportICS([]Todo{{ID: "a", Text: text}})
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line split inside a UTF-8 sequence: %q", line)
+		}
+	}
+	// unfolding removes each CRLF followed by a space
+	unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+	if !strings.Contains(unfolded, "\r\nSUMMARY:"+text+"\r\n") {
+		t.Errorf("unfolded ics = %q, want the whole summary back", unfolded)
+	}
+	if !strings.Contains(ics, "\r\n ") {
+		t.Errorf("ics = %q, want the long summary folded", ics)
+	}
+}
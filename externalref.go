@@ -0,0 +1,21 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ParseExternalRef validates a link to an external ticket or issue and returns
+// it trimmed. Only absolute http and https URLs are accepted; an empty ref is
+// returned as is and unlinks the todo.
+func ParseExternalRef(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", nil
+	}
+	u, err := url.ParseRequestURI(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", NewCodedError(ErrCodeValidationError, "external ref %q is not an http or https URL", ref)
+	}
+	return ref, nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSetExternalRef(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "linked"})
+
+	var data struct{ SetExternalRef Todo }
+	mustExecute(t, `mutation{setExternalRef(id:"a",url:"  https://github.com/o/r/issues/7 "){externalRef}}`, &data)
+	if data.SetExternalRef.ExternalRef != "https://github.com/o/r/issues/7" {
+		t.Errorf("externalRef = %q, want the trimmed URL", data.SetExternalRef.ExternalRef)
+	}
+
+	for _, url := range []string{"not a url", "github.com/o/r/issues/7", "ftp://example.com/x", "https://", "/relative/path", "javascript:alert(1)"} {
+		query := `mutation{setExternalRef(id:"a",url:` + strconv.Quote(url) + `){id}}`
+		if code := errorCode(t, execute(t, query)); code != ErrCodeValidationError {
+			t.Errorf("%q: code = %q, want %s", url, code, ErrCodeValidationError)
+		}
+	}
+	if todo, _, _ := store.Get("a"); todo.ExternalRef != "https://github.com/o/r/issues/7" {
+		t.Errorf("externalRef = %q, want the valid link kept", todo.ExternalRef)
+	}
+
+	mustExecute(t, `mutation{setExternalRef(id:"a",url:""){externalRef}}`, &data)
+	if data.SetExternalRef.ExternalRef != "" {
+		t.Errorf("externalRef = %q, want the link removed", data.SetExternalRef.ExternalRef)
+	}
+	if code := errorCode(t, execute(t, `mutation{setExternalRef(id:"nope",url:"https://example.com"){id}}`)); code != ErrCodeNotFound {
+		t.Errorf("unknown id: code = %q, want %s", code, ErrCodeNotFound)
+	}
+}
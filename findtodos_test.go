@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindTodos(t *testing.T) {
+	resetTodos(t,
+		Todo{ID: "a", Text: "Write report", Done: false, Assignee: "alice", Tags: []string{"work"}},
+		Todo{ID: "b", Text: "Read report", Done: true, Assignee: "alice", Tags: []string{"work"}},
+		Todo{ID: "c", Text: "Buy milk", Done: false, Assignee: "bob", Tags: []string{"home"}},
+		Todo{ID: "d", Text: "Water plants", Done: false, Tags: []string{"home", "work"}},
+	)
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{"no filters", ``, "a b c d"},
+		{"done", `(done:true)`, "b"},
+		{"not done", `(done:false)`, "a c d"},
+		{"assignee", `(assignee:"alice")`, "a b"},
+		{"unassigned", `(assignee:"")`, "d"},
+		{"tag", `(tag:"home")`, "c d"},
+		{"text ignores case", `(text:"REPORT")`, "a b"},
+		{"done and tag", `(done:false,tag:"work")`, "a d"},
+		{"assignee and text", `(assignee:"alice",text:"read")`, "b"},
+		{"all four", `(done:false,assignee:"alice",tag:"work",text:"write")`, "a"},
+		{"no match", `(tag:"home",assignee:"alice")`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data struct{ FindTodos []Todo }
+			mustExecute(t, `{findTodos`+tt.args+`{id}}`, &data)
+			if got := strings.Join(todoIDs(data.FindTodos), " "); got != tt.want {
+				t.Errorf("findTodos%s = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestGraphiQLToggle(t *testing.T) {
+	resetTodos(t)
+	schema, err := BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, enabled := range []bool{true, false} {
+		h := GraphQLHandler(schema, enabled)
+		r := httptest.NewRequest(http.MethodGet, "/graphql", nil)
+		r.Header.Set("Accept", "text/html,application/xhtml+xml")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		servesIDE := strings.Contains(w.Header().Get("Content-Type"), "text/html") &&
+			strings.Contains(strings.ToLower(w.Body.String()), "graphiql")
+		if servesIDE != enabled {
+			t.Errorf("GraphiQL %v: served the IDE = %v (%s)", enabled, servesIDE, w.Header().Get("Content-Type"))
+		}
+		if !enabled && strings.Contains(w.Body.String(), "<html") {
+			t.Errorf("GraphiQL disabled: body = %.80s", w.Body)
+		}
+	}
+}
+
+func TestGraphQLHandlerServesQueries(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "served"})
+	schema, err := BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, enabled := range []bool{true, false} {
+		h := GraphQLHandler(schema, enabled)
+		for _, pretty := range []string{"true", "false"} {
+			r := httptest.NewRequest(http.MethodGet, "/graphql?pretty="+pretty+"&query="+url.QueryEscape(`{todoList{id}}`), nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			body := strings.TrimSpace(w.Body.String())
+			if !strings.Contains(strings.ReplaceAll(body, " ", ""), `"id":"a"`) {
+				t.Fatalf("GraphiQL %v, pretty %s: body = %s", enabled, pretty, body)
+			}
+			if indented := strings.Contains(body, "\n"); indented != (pretty == "true") {
+				t.Errorf("GraphiQL %v, pretty %s: indented = %v", enabled, pretty, indented)
+			}
+		}
+	}
+}
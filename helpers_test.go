@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/handler"
+)
+
+// resetTodos gives the test a fresh in-memory store holding todos, and no snapshots.
+func resetTodos(t *testing.T, todos ...Todo) {
+	t.Helper()
+	store = NewMemStore()
+	snapshots = nil
+	if err := store.Add(todos...); err != nil {
+		t.Fatalf("add todos: %v", err)
+	}
+}
+
+// allowSnapshots adds the snapshot admin mutations to schemas built by the test.
+func allowSnapshots(t *testing.T) {
+	t.Helper()
+	snapshotMutations = true
+	t.Cleanup(func() { snapshotMutations = false })
+}
+
+// execute runs query through graphql.Do against a freshly built schema.
+func execute(t *testing.T, query string) *graphql.Result {
+	t.Helper()
+	return executeContext(t, context.Background(), query)
+}
+
+// executeContext is execute with the given request context.
+func executeContext(t *testing.T, ctx context.Context, query string) *graphql.Result {
+	t.Helper()
+	schema, err := BuildSchema()
+	if err != nil {
+		t.Fatalf("build schema: %v", err)
+	}
+	return graphql.Do(graphql.Params{Schema: schema, RequestString: query, Context: ctx})
+}
+
+// graphqlHandler returns the plain GraphQL HTTP handler for a freshly built
+// schema, for wrapping in the middleware under test.
+func graphqlHandler(t *testing.T) *handler.Handler {
+	t.Helper()
+	schema, err := BuildSchema()
+	if err != nil {
+		t.Fatalf("build schema: %v", err)
+	}
+	return handler.New(&handler.Config{Schema: &schema})
+}
+
+// mustExecute runs query and decodes its data into out, failing the test when
+// the result has errors.
+func mustExecute(t *testing.T, query string, out interface{}) {
+	t.Helper()
+	result := execute(t, query)
+	if result.HasErrors() {
+		t.Fatalf("%s: %v", query, result.Errors)
+	}
+	decodeData(t, result, out)
+}
+
+// decodeData decodes the data of result into out by way of its JSON encoding.
+func decodeData(t *testing.T, result *graphql.Result, out interface{}) {
+	t.Helper()
+	data, err := json.Marshal(result.Data)
+	if err != nil {
+		t.Fatalf("encode data: %v", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatalf("decode data %s: %v", data, err)
+	}
+}
+
+// errorCode returns the `extensions.code` of the single error of result.
+func errorCode(t *testing.T, result *graphql.Result) string {
+	t.Helper()
+	if len(result.Errors) != 1 {
+		t.Fatalf("got %d errors, want 1: %v", len(result.Errors), result.Errors)
+	}
+	code, _ := result.Errors[0].Extensions["code"].(string)
+	return code
+}
+
+// todoIDs returns the ids of todos, in order.
+func todoIDs(todos []Todo) []string {
+	ids := make([]string, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+	return ids
+}
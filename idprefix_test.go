@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateTodoIDPrefix(t *testing.T) {
+	resetTodos(t)
+	saved := idPrefixes["todo"]
+	idPrefixes["todo"] = "task-"
+	t.Cleanup(func() { idPrefixes["todo"] = saved })
+
+	var data struct{ CreateTodo Todo }
+	mustExecute(t, `mutation{createTodo(text:"prefixed",task:"t"){id}}`, &data)
+	id := data.CreateTodo.ID
+	if !strings.HasPrefix(id, "task-") || len(id) != len("task-")+idLength {
+		t.Fatalf("id = %q, want task- and %d random runes", id, idLength)
+	}
+
+	// the id may be passed back with or without its prefix
+	bare := StripIDPrefix("todo", id)
+	var found struct{ Todo *Todo }
+	mustExecute(t, `{todo(id:"`+bare+`"){id}}`, &found)
+	if found.Todo == nil || found.Todo.ID != id {
+		t.Errorf("todo(%q) = %+v, want %s", bare, found.Todo, id)
+	}
+}
+
+func TestNewIDPrefixes(t *testing.T) {
+	for kind, prefix := range idPrefixes {
+		if id := NewID(kind); !strings.HasPrefix(id, prefix) || StripIDPrefix(kind, id) == id {
+			t.Errorf("NewID(%q) = %q, want prefix %q", kind, id, prefix)
+		}
+	}
+	if !sameTodoID("todo_abc", "abc") || sameTodoID("todo_abc", "abd") {
+		t.Error("sameTodoID doesn't ignore the prefix")
+	}
+}
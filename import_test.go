@@ -0,0 +1,77 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// importQuery is the importTodos mutation for the JSON payload.
+func importQuery(payload string) string {
+	return `mutation{importTodos(json:` + strconv.Quote(payload) + `)}`
+}
+
+// storedIDs returns the ids of the todos in the store, in order.
+func storedIDs(t *testing.T) []string {
+	t.Helper()
+	todos, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return todoIDs(todos)
+}
+
+func TestImportTodos(t *testing.T) {
+	resetTodos(t)
+	var data struct{ ImportTodos int }
+	mustExecute(t, importQuery(`[{"id":"x","text":"one"},{"id":"y","text":"two","done":true}]`), &data)
+	if data.ImportTodos != 2 {
+		t.Errorf("imported %d, want 2", data.ImportTodos)
+	}
+	todo, _, _ := store.Get("y")
+	if todo.Text != "two" || !todo.Done || todo.Version != 1 || todo.CreatedAt.IsZero() {
+		t.Errorf("imported todo = %+v", todo)
+	}
+}
+
+func TestImportTodosWithoutIDs(t *testing.T) {
+	resetTodos(t)
+	var data struct{ ImportTodos int }
+	mustExecute(t, importQuery(`[{"id":"kept","text":"one"},{"text":"two"},{"text":"three"}]`), &data)
+	ids := storedIDs(t)
+	if data.ImportTodos != 3 || len(ids) != 3 {
+		t.Fatalf("imported %d, stored %v, want 3", data.ImportTodos, ids)
+	}
+	if ids[0] != "kept" {
+		t.Errorf("id = %q, want kept", ids[0])
+	}
+	if !strings.HasPrefix(ids[1], idPrefixes["todo"]) || !strings.HasPrefix(ids[2], idPrefixes["todo"]) || ids[1] == ids[2] {
+		t.Errorf("generated ids %v, want two fresh prefixed ids", ids[1:])
+	}
+}
+
+func TestImportTodosRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{"malformed JSON", `[{"text":`},
+		{"id repeated in payload", `[{"id":"x","text":"one"},{"id":"x","text":"two"}]`},
+		{"id taken by a stored todo", `[{"id":"new","text":"one"},{"id":"a","text":"two"}]`},
+		{"taken id with prefix", `[{"id":"` + idPrefixes["todo"] + `a","text":"one"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTodos(t, Todo{ID: "a", Text: "stored"})
+			result := execute(t, importQuery(tt.payload))
+			if code := errorCode(t, result); code != ErrCodeValidationError {
+				t.Errorf("code = %q, want %s", code, ErrCodeValidationError)
+			}
+			// nothing changes on error
+			if ids := storedIDs(t); !reflect.DeepEqual(ids, []string{"a"}) {
+				t.Errorf("stored ids = %v, want [a]", ids)
+			}
+		})
+	}
+}
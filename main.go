@@ -1,23 +1,40 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math/rand"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/graphql-go/graphql"
-	"github.com/graphql-go/handler"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/time/rate"
 )
 
 type Todo struct {
-	ID   string `json:"id"`
-	Text string `json:"text"`
-	Done bool   `json:"done"`
-	Task string `json:"task"`
+	ID          string       `json:"id"`
+	Text        string       `json:"text"`
+	Done        bool         `json:"done"`
+	Task        string       `json:"task"`
+	Tags        []string     `json:"tags"`
+	Assignee    string       `json:"assignee"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+	DueDate     *time.Time   `json:"dueDate"`
+	CompletedAt *time.Time   `json:"completedAt"`
+	Attachments []Attachment `json:"attachments"`
+	StoryPoints int          `json:"storyPoints"`
+	Version     int          `json:"version"`
+	ExternalRef string       `json:"externalRef"`
+	Archived    bool         `json:"archived"`
 }
 
-var TodoList []Todo
+// todoMu serializes changes to the store. Resolvers of concurrent HTTP requests
+// run in parallel, so every resolver that reads or changes the todos takes it first.
+var todoMu sync.RWMutex
 var letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
 
 func RandStringRunes(n int) string {
@@ -28,189 +45,288 @@ func RandStringRunes(n int) string {
 	return string(b)
 }
 
-func init() {
-	todo1 := Todo{ID: "a", Text: "A todo not to forget", Done: false}
-	todo2 := Todo{ID: "b", Text: "This is the most important", Done: false}
-	todo3 := Todo{ID: "c", Text: "Please do this or else", Done: false}
-	TodoList = append(TodoList, todo1, todo2, todo3)
+// idLength is the number of random runes in a generated id, not counting the type prefix.
+const idLength = 8
+
+// idPrefixes maps an entity type to the prefix put in front of its generated ids,
+// so an id like "todo_xYzAbCdE" says what kind of object it belongs to.
+var idPrefixes = map[string]string{
+	"todo":       "todo_",
+	"attachment": "att_",
+}
+
+// NewID generates a random id for the given entity type, including its prefix.
+func NewID(kind string) string {
+	return idPrefixes[kind] + RandStringRunes(idLength)
+}
+
+// StripIDPrefix removes the type prefix of kind from id, if it has one.
+func StripIDPrefix(kind, id string) string {
+	return strings.TrimPrefix(id, idPrefixes[kind])
+}
+
+// sameTodoID reports whether two todo ids refer to the same todo,
+// ignoring whether either of them carries the todo prefix.
+func sameTodoID(a, b string) bool {
+	return StripIDPrefix("todo", a) == StripIDPrefix("todo", b)
+}
+
+// NewTodoFromArgs builds a new todo from the createTodo arguments (or the
+// fields of a TodoInput). Without a dueDate, one may be parsed from the text,
+// see parseDueDates.
+func NewTodoFromArgs(args map[string]interface{}, now time.Time) (Todo, error) {
+	text, _ := args["text"].(string)
+	task, _ := args["task"].(string)
+	tags := stringList(args["tags"])
+	assignee, _ := args["assignee"].(string)
+	if strings.TrimSpace(text) == "" {
+		return Todo{}, NewCodedError(ErrCodeValidationError, "text must not be empty")
+	}
+
+	var dueDate *time.Time
+	if due, ok := args["dueDate"].(time.Time); ok {
+		dueDate = &due
+	} else if parseDueDates {
+		if due, stripped, ok := ParseDueDate(text, now); ok {
+			dueDate = &due
+			if stripDuePhrases && stripped != "" {
+				text = stripped
+			}
+		}
+	}
+
+	todo := Todo{
+		ID:        NewID("todo"),
+		Text:      text,
+		Task:      task,
+		Tags:      tags,
+		Assignee:  assignee,
+		CreatedAt: now,
+		UpdatedAt: now,
+		DueDate:   dueDate,
+		Version:   1,
+	}
+	return todo, nil
+}
+
+// TodoBatchResult is returned by mutations that change several todos by id.
+type TodoBatchResult struct {
+	Todos    []Todo   `json:"todos"`
+	NotFound []string `json:"notFound"`
+}
+
+// TodoFilter selects todos by the criteria that are set; nil fields match everything.
+type TodoFilter struct {
+	Done     *bool
+	Assignee *string
+	Tag      *string
+	// Text matches todos whose text contains it, ignoring case.
+	Text *string
+}
+
+// Matches reports whether todo satisfies every criterion set in f.
+func (f TodoFilter) Matches(todo Todo) bool {
+	if f.Done != nil && todo.Done != *f.Done {
+		return false
+	}
+	if f.Assignee != nil && todo.Assignee != *f.Assignee {
+		return false
+	}
+	if f.Tag != nil && !todo.hasTag(*f.Tag) {
+		return false
+	}
+	if f.Text != nil && !strings.Contains(strings.ToLower(todo.Text), strings.ToLower(*f.Text)) {
+		return false
+	}
+	return true
+}
+
+// touch records a change to todo made at now: it refreshes UpdatedAt and bumps the version.
+func (todo *Todo) touch(now time.Time) {
+	todo.UpdatedAt = now
+	todo.Version++
+}
+
+// setDone marks todo done or not done. CompletedAt is set when the todo
+// becomes done and cleared when it is reopened; marking a done todo done again
+// keeps the original completion time. It reports whether the todo became done.
+func (todo *Todo) setDone(done bool, now time.Time) bool {
+	completed := done && !todo.Done
+	switch {
+	case completed:
+		todo.CompletedAt = &now
+	case !done:
+		todo.CompletedAt = nil
+	}
+	todo.Done = done
+	return completed
+}
+
+// addTags adds the given tags to todo, skipping any it already has.
+// It reports whether any tag was added.
+func (todo *Todo) addTags(tags []string) bool {
+	added := false
+	for _, tag := range tags {
+		if !todo.hasTag(tag) {
+			todo.Tags = append(todo.Tags, tag)
+			added = true
+		}
+	}
+	return added
+}
 
+// stringList converts a GraphQL list argument into a []string.
+func stringList(arg interface{}) []string {
+	values, _ := arg.([]interface{})
+	list := make([]string, 0, len(values))
+	for _, v := range values {
+		if str, ok := v.(string); ok {
+			list = append(list, str)
+		}
+	}
+	return list
+}
+
+// hasTag reports whether todo is labelled with tag. Matching is exact and case-sensitive.
+func (todo Todo) hasTag(tag string) bool {
+	for _, t := range todo.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// seedTodos adds a few sample todos to an empty store, handy during development.
+// A store that already has todos, like a reopened database, is left alone.
+func seedTodos() error {
+	now := time.Now().UTC()
+	todo1 := Todo{ID: "a", Text: "A todo not to forget", Done: false, CreatedAt: now, UpdatedAt: now, Version: 1}
+	todo2 := Todo{ID: "b", Text: "This is the most important", Done: false, CreatedAt: now, UpdatedAt: now, Version: 1}
+	todo3 := Todo{ID: "c", Text: "Please do this or else", Done: false, CreatedAt: now, UpdatedAt: now, Version: 1}
+	todoMu.Lock()
+	defer todoMu.Unlock()
+	todos, err := store.List()
+	if err != nil || len(todos) > 0 {
+		return err
+	}
+	return store.Add(todo1, todo2, todo3)
+}
+
+func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
 func main() {
+	seed := flag.Bool("seed", true, "start with sample todos; disable for production")
+	todoIDPrefix := flag.String("todo-id-prefix", idPrefixes["todo"], "prefix for generated todo ids")
+	digestInterval := flag.Duration("digest-interval", 0, "how often to log a digest of completed todos (0 disables it)")
+	printSchema := flag.Bool("print-schema", false, "print the schema introspection JSON to stdout and exit")
+	flag.IntVar(&maxAttachmentSize, "max-attachment-size", maxAttachmentSize, "largest attachment size in bytes")
+	storyPoints := flag.String("story-points", "1,2,3,5,8", "comma separated story point values setStoryPoints accepts")
+	flag.IntVar(&pointsTarget, "points-target", pointsTarget, "story points a window must complete for pointsTarget to report it met")
+	flag.BoolVar(&snapshotMutations, "snapshots", snapshotMutations, "add the createSnapshot and restoreSnapshot admin mutations; anyone allowed on /graphql may call them, so only enable behind trusted access")
+	flag.IntVar(&maxSnapshots, "max-snapshots", maxSnapshots, "number of snapshots to retain")
+	flag.DurationVar(&staleAfter, "stale-after", staleAfter, "how long a not done todo may go without an update before staleTodos lists it")
+	maxBody := flag.Int64("max-body", 1<<20, "maximum request body size in bytes")
+	maxURLQuery := flag.Int("max-url-query", 8192, "maximum length in bytes of the URL query string of GET requests")
+	maxDepth := flag.Int("max-depth", 10, "maximum field nesting depth of a query, introspection included; GraphiQL's schema query needs 13")
+	flag.BoolVar(&parseDueDates, "parse-due-dates", false, "take a todo's due date from phrases like \"tomorrow\" in its text")
+	flag.BoolVar(&stripDuePhrases, "strip-due-phrases", false, "remove the parsed due date phrase from the todo text")
+	graphiql := flag.Bool("graphiql", true, "serve the GraphiQL IDE to browsers on /graphql; disable in production")
+	authToken := flag.String("auth-token", "", "require this shared bearer token on /graphql requests; auth is disabled when neither it nor -auth-users is set")
+	authUsers := flag.String("auth-users", "", "comma separated name:token pairs of bearer tokens identifying their holder; only the assignee may mark a todo done, and the shared -auth-token then only unassigned ones")
+	denyFields := flag.String("deny-fields", "", "comma separated root fields to leave out of the schema, e.g. clearCompleted")
+	deprecationWarnings := flag.Bool("deprecation-warnings", false, "list deprecated fields selected by a query in the response extensions")
+	rateLimit := flag.Float64("rate", 10, "requests per second allowed per client IP (0 disables rate limiting)")
+	rateBurst := flag.Int("burst", 20, "requests a client IP may send in a burst above -rate")
+	db := flag.String("db", "mem", "where to keep the todos: mem, or a SQLite data source name such as todos.db")
+	statusSync := flag.String("status-sync", "", "mark todos done when their externalRef is closed, looked up with this adapter: "+strings.Join(statusAdapterNames(), ", ")+" (empty disables it)")
+	statusSyncToken := flag.String("status-sync-token", "", "API token for the status sync adapter")
+	statusSyncInterval := flag.Duration("status-sync-interval", 5*time.Minute, "how often to sync todo status from external refs")
+	statusSyncRate := flag.Float64("status-sync-rate", 1, "maximum external status lookups per second (0 for no limit)")
+	statusSyncTimeout := flag.Duration("status-sync-timeout", 10*time.Second, "timeout of a single external status lookup")
+	flag.Parse()
+	idPrefixes["todo"] = *todoIDPrefix
+	points, err := parseStoryPoints(*storyPoints)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	allowedStoryPoints = points
 
-	// fmt.Println("============> helloWorld ", HelloWorld())
-	// define custom GraphQL ObjectType `todoType` for our Golang struct `Todo`
-	// Note that
-	// - the fields in our todoType maps with the json tags for the fields in our struct
-	// - the field type matches the field type in our struct
-	todoType := graphql.NewObject(graphql.ObjectConfig{
-		Name: "Todo",
-		Fields: graphql.Fields{
-			"id": &graphql.Field{
-				Type: graphql.String,
-			},
-			"text": &graphql.Field{
-				Type: graphql.String,
-			},
-			"done": &graphql.Field{
-				Type: graphql.Boolean,
-			},
-			"task": &graphql.Field{
-				Type: graphql.String,
-			},
-		},
-	})
-
-	// root mutation
-	rootMutation := graphql.NewObject(graphql.ObjectConfig{
-		Name: "RootMutation",
-		Fields: graphql.Fields{
-			"createTodo": &graphql.Field{
-				Type: todoType, // the return type for this field
-				Args: graphql.FieldConfigArgument{
-					"text": &graphql.ArgumentConfig{
-						Type: graphql.NewNonNull(graphql.String),
-					},
-					"task": &graphql.ArgumentConfig{
-						Type: graphql.NewNonNull(graphql.String),
-					},
-				},
-				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
-
-					// marshall and cast the argument value
-					text, _ := params.Args["text"].(string)
-					task, _ := params.Args["task"].(string)
-					// perform mutation operation here
-					// for e.g. create a Todo and save to DB.
-
-					newTodo := Todo{
-						ID:   "id0001",
-						Text: text,
-						Done: true,
-						Task: task,
-					}
-					fmt.Println("------------------> ", newTodo)
-					// return the new Todo object that we supposedly save to DB
-					// Note here that
-					// - we are returning a `Todo` struct instance here
-					// - we previously specified the return Type to be `todoType`
-					// - `Todo` struct maps to `todoType`, as defined in `todoType` ObjectConfig`
-					// TodoList = append(TodoList, newTodo)
-					TodoList = append(TodoList, newTodo)
-					return TodoList, nil
-				},
-			},
-
-			//update opration of TODO
-			"updateTodo": &graphql.Field{
-				Type:        todoType, // the return type for this field
-				Description: "Update existing todo, mark it done or not done",
-				Args: graphql.FieldConfigArgument{
-					"done": &graphql.ArgumentConfig{
-						Type: graphql.Boolean,
-					},
-					"id": &graphql.ArgumentConfig{
-						Type: graphql.NewNonNull(graphql.String),
-					},
-				},
-				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
-					// marshall and cast the argument value
-					done, _ := params.Args["done"].(bool)
-					id, _ := params.Args["id"].(string)
-					affectedTodo := Todo{}
-
-					// Search list for todo with id and change the done variable
-					for i := 0; i < len(TodoList); i++ {
-						if TodoList[i].ID == id {
-							TodoList[i].Done = done
-							// Assign updated todo so we can return it
-							affectedTodo = TodoList[i]
-							break
-						}
-					}
-					// Return affected todo
-					return affectedTodo, nil
-				},
-			},
-		},
-	})
-
-	// root query
-	// we just define a trivial example here, since root query is required.
-	// Test with curl
-	// curl -g 'http://localhost:8080/graphql?query={lastTodo{id,text,done}}'
-	var rootQuery = graphql.NewObject(graphql.ObjectConfig{
-		Name: "RootQuery",
-		Fields: graphql.Fields{
-
-			/*
-			   curl -g 'http://localhost:8080/graphql?query={todo(id:"b"){id,text,done}}'
-			*/
-			"todo": &graphql.Field{
-				Type:        todoType,
-				Description: "Get single todo",
-				Args: graphql.FieldConfigArgument{
-					"id": &graphql.ArgumentConfig{
-						Type: graphql.String,
-					},
-				},
-				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
-
-					idQuery, isOK := params.Args["id"].(string)
-					if isOK {
-						// Search for el with id
-						for _, todo := range TodoList {
-							if todo.ID == idQuery {
-								return todo, nil
-							}
-						}
-					}
-
-					return Todo{}, nil
-				},
-			},
-
-			"lastTodo": &graphql.Field{
-				Type:        todoType,
-				Description: "Last todo added",
-				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
-					return TodoList[len(TodoList)-1], nil
-				},
-			},
-
-			/*
-			   curl -g 'http://localhost:8080/graphql?query={todoList{id,text,done}}'
-			*/
-			"todoList": &graphql.Field{
-				Type:        graphql.NewList(todoType),
-				Description: "List of todos",
-				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
-					return TodoList, nil
-				},
-			},
-		},
-	})
-
-	// define schema
-	schema, err := graphql.NewSchema(graphql.SchemaConfig{
-		Query:    rootQuery,
-		Mutation: rootMutation,
-	})
+	creds, err := parseCredentials(*authUsers)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *authToken != "" {
+		// the shared token is accepted but doesn't identify its holder, so
+		// next to -auth-users it may not complete assigned todos
+		if _, taken := creds[*authToken]; !taken {
+			creds[*authToken] = ""
+		}
+	}
 
+	deniedFields = parseFieldList(*denyFields)
+	schema, err := BuildSchema()
 	if err != nil {
-		panic(err)
+		fmt.Fprintf(os.Stderr, "build schema: %v\n", err)
+		os.Exit(2)
+	}
+	if *deprecationWarnings {
+		schema.AddExtensions(DeprecationWarnings{})
+	}
+
+	if *printSchema {
+		if err := PrintSchema(os.Stdout, schema); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	h := handler.New(&handler.Config{
-		Schema:   &schema,
-		Pretty:   true,
-		GraphiQL: true,
-	})
+	store, err = OpenStore(*db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open store %q: %v\n", *db, err)
+		os.Exit(1)
+	}
+	if *seed {
+		if err := seedTodos(); err != nil {
+			fmt.Fprintf(os.Stderr, "seed todos: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *digestInterval > 0 {
+		completionDigest = NewCompletionDigest(time.Now())
+		completionDigest.Start(*digestInterval)
+	}
+	if *statusSync != "" {
+		newAdapter, ok := statusAdapters[*statusSync]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown status sync adapter %q, use one of %v\n", *statusSync, statusAdapterNames())
+			os.Exit(2)
+		}
+		syncer := &StatusSync{Adapter: newAdapter(*statusSyncToken), Timeout: *statusSyncTimeout}
+		if *statusSyncRate > 0 {
+			syncer.Limiter = rate.NewLimiter(rate.Limit(*statusSyncRate), 1)
+		}
+		syncer.Start(*statusSyncInterval)
+	}
+
+	h := GraphQLHandler(schema, *graphiql)
+
+	var limiter *IPRateLimiter
+	if *rateLimit > 0 {
+		limiter = NewIPRateLimiter(*rateLimit, *rateBurst)
+		limiter.StartReaper(time.Minute, 10*time.Minute)
+	}
 
 	// serve HTTP
-	http.Handle("/graphql", h)
-	http.ListenAndServe(":8080", nil)
+	http.Handle("/graphql", RateLimit(limiter, GuardRequestLine(*maxURLQuery, BearerAuth(creds, MaxBodySize(*maxBody, LogRequests(MaxQueryDepth(*maxDepth, RejectSubscriptions(h))))))))
+	http.Handle("/subscriptions", RateLimit(limiter, BearerAuth(creds, SubscriptionHandler(schema))))
+	http.Handle("/metrics", promhttp.Handler())
+	http.ListenAndServe(":8080", Recover(http.DefaultServeMux))
 	fmt.Println("Now server is running on port 8080")
 
 	// How to make a HTTP request using cUrl
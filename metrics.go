@@ -0,0 +1,111 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	operationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "graphql_operations_total",
+		Help: "GraphQL requests served, by operation name.",
+	}, []string{"operation"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "graphql_request_duration_seconds",
+		Help:    "Time taken to serve GraphQL requests, by operation name.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation"})
+)
+
+// maxOperationLabels caps the distinct operation names the metrics are
+// labelled with. Operation names are chosen by clients, so without a cap every
+// new name would add series that are never freed.
+const maxOperationLabels = 100
+
+// operationLabels holds the operation names already used as metric labels.
+var operationLabels = struct {
+	sync.Mutex
+	seen map[string]bool
+}{seen: map[string]bool{}}
+
+// operationLabel returns the metric label for name: name itself while fewer
+// than maxOperationLabels names are in use or it is one of them, else "other".
+func operationLabel(name string) string {
+	operationLabels.Lock()
+	defer operationLabels.Unlock()
+	if !operationLabels.seen[name] {
+		if len(operationLabels.seen) >= maxOperationLabels {
+			return "other"
+		}
+		operationLabels.seen[name] = true
+	}
+	return name
+}
+
+// operationName names the operation a request runs, as defined in its query:
+// the one selected by operationName, else the first one. Unnamed operations
+// are "anonymous"; requests without a valid query, or whose operationName
+// matches no operation of the query, are "other".
+func operationName(parsed *parsedRequest) string {
+	if parsed == nil || parsed.Document == nil {
+		return "other"
+	}
+	for _, def := range parsed.Document.Definitions {
+		operation, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		name := ""
+		if operation.Name != nil {
+			name = operation.Name.Value
+		}
+		if parsed.Options.OperationName == "" {
+			if name == "" {
+				return "anonymous"
+			}
+			return name
+		}
+		if name == parsed.Options.OperationName {
+			return name
+		}
+	}
+	return "other"
+}
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// LogRequests logs every GraphQL request with its operation, status and
+// duration, and records them in the Prometheus metrics served on /metrics.
+func LogRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		r, parsed, _ := parseRequest(r)
+		operation := operationName(parsed)
+		if operation != "anonymous" && operation != "other" {
+			operation = operationLabel(operation)
+		}
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		elapsed := time.Since(start)
+
+		operationsTotal.WithLabelValues(operation).Inc()
+		requestDuration.WithLabelValues(operation).Observe(elapsed.Seconds())
+		log.Printf("%s %s operation=%s status=%d duration=%s", r.Method, r.URL.Path, operation, recorder.status, elapsed)
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/handler"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// scrapeCounter returns the value of graphql_operations_total for operation
+// as served on /metrics, 0 when the series does not exist yet.
+func scrapeCounter(t *testing.T, operation string) float64 {
+	t.Helper()
+	w := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	series := fmt.Sprintf(`graphql_operations_total{operation=%q} `, operation)
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), series); ok {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				t.Fatalf("parse %q: %v", scanner.Text(), err)
+			}
+			return v
+		}
+	}
+	return 0
+}
+
+func TestMetricsCountOperations(t *testing.T) {
+	resetTodos(t)
+	schema, err := BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := LogRequests(handler.New(&handler.Config{Schema: &schema}))
+	get := func(query, operationName string) {
+		target := "/graphql?query=" + url.QueryEscape(query)
+		if operationName != "" {
+			target += "&operationName=" + url.QueryEscape(operationName)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body)
+		}
+	}
+
+	before := scrapeCounter(t, "ListTodos")
+	beforeOther := scrapeCounter(t, "other")
+	get(`query ListTodos { todoList { id } }`, "")
+	get(`query Other { lastTodo { id } } query ListTodos { todoList { id } }`, "ListTodos")
+	// an operationName the query does not define must not become a label
+	get(`query ListTodos { todoList { id } }`, "made-up")
+
+	if got := scrapeCounter(t, "ListTodos") - before; got != 2 {
+		t.Errorf("ListTodos counter increased by %v, want 2", got)
+	}
+	if got := scrapeCounter(t, "other") - beforeOther; got != 1 {
+		t.Errorf("other counter increased by %v, want 1", got)
+	}
+	if got := scrapeCounter(t, "made-up"); got != 0 {
+		t.Errorf("made-up counter = %v, want no series", got)
+	}
+}
+
+func TestOperationLabelCap(t *testing.T) {
+	operationLabel("Kept")
+	for i := 0; i < maxOperationLabels+10; i++ {
+		operationLabel(fmt.Sprintf("Op%d", i))
+	}
+	if got := operationLabel("OneTooMany"); got != "other" {
+		t.Errorf("label past the cap = %q, want other", got)
+	}
+	if got := operationLabel("Kept"); got != "Kept" {
+		t.Errorf("label seen before the cap = %q, want Kept", got)
+	}
+}
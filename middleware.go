@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/handler"
+)
+
+// writeGraphQLError answers a request that never reached the GraphQL handler
+// with a GraphQL shaped error body, so clients can handle it like any other error.
+func writeGraphQLError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []gqlerrors.FormattedError{gqlerrors.NewFormattedError(message)},
+	})
+}
+
+// Recover turns a panic anywhere below it into a 500 Internal Server Error with
+// a GraphQL shaped error body, instead of the dropped connection net/http
+// leaves behind. The panic and stack are only logged. http.ErrAbortHandler is
+// passed on, since it is the way to abort a response on purpose.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if p := recover(); p != nil {
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+				log.Printf("panic serving %s %s: %v\n%s", r.Method, r.URL.Path, p, debug.Stack())
+				writeGraphQLError(w, http.StatusInternalServerError, "internal server error")
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MaxBodySize caps request bodies at limit bytes, answering
+// 413 Request Entity Too Large when a client sends more.
+// The body is read up front because the GraphQL handler swallows read errors.
+func MaxBodySize(limit int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// wantsPretty reports whether r asks for indented JSON. Clients opt out with
+// `?pretty=false` or an `X-Pretty: false` header; anything else keeps the default.
+func wantsPretty(r *http.Request) bool {
+	value := r.URL.Query().Get("pretty")
+	if value == "" {
+		value = r.Header.Get("X-Pretty")
+	}
+	if pretty, err := strconv.ParseBool(value); err == nil {
+		return pretty
+	}
+	return true
+}
+
+// PrettyToggle sends each request to the pretty or the compact handler,
+// depending on what the client asked for.
+func PrettyToggle(pretty, compact http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wantsPretty(r) {
+			pretty.ServeHTTP(w, r)
+			return
+		}
+		compact.ServeHTTP(w, r)
+	})
+}
+
+// GraphQLHandler serves queries and mutations on schema, pretty printing the
+// results unless the client opts out. graphiql serves the GraphiQL IDE to
+// browsers, as the -graphiql flag asks.
+func GraphQLHandler(schema graphql.Schema, graphiql bool) http.Handler {
+	newHandler := func(pretty bool) http.Handler {
+		return handler.New(&handler.Config{
+			Schema:   &schema,
+			Pretty:   pretty,
+			GraphiQL: graphiql,
+		})
+	}
+	return PrettyToggle(newHandler(true), newHandler(false))
+}
+
+// allowedMethods are the HTTP methods the GraphQL endpoint answers.
+var allowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodOptions}
+
+// GuardRequestLine answers 405 Method Not Allowed for methods other than GET,
+// POST and OPTIONS, and 414 URI Too Long for GET requests whose URL query
+// string is longer than maxQuery bytes. OPTIONS is answered here with 204 No
+// Content and the allowed methods, so it never reaches next, which would run
+// a query given in its URL.
+func GuardRequestLine(maxQuery int, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed := false
+		for _, method := range allowedMethods {
+			if r.Method == method {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			w.Header().Set("Allow", strings.Join(allowedMethods, ", "))
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Allow", strings.Join(allowedMethods, ", "))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.Method == http.MethodGet && len(r.URL.RawQuery) > maxQuery {
+			http.Error(w, http.StatusText(http.StatusRequestURITooLong), http.StatusRequestURITooLong)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMoveTodo(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		toIndex int
+		want    string
+	}{
+		{"to the front", "c", 0, "c a b d"},
+		{"to the back", "a", 3, "b c d a"},
+		{"to the middle", "d", 1, "a d b c"},
+		{"in place", "b", 1, "a b c d"},
+		{"past the end", "b", 99, "a c d b"},
+		{"before the start", "d", -5, "d a b c"},
+		{"prefixed id", "todo_c", 0, "c a b d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTodos(t, Todo{ID: "a"}, Todo{ID: "b"}, Todo{ID: "c"}, Todo{ID: "d"})
+			var data struct{ MoveTodo []Todo }
+			mustExecute(t, fmt.Sprintf(`mutation{moveTodo(id:%q,toIndex:%d){id}}`, tt.id, tt.toIndex), &data)
+			if got := strings.Join(todoIDs(data.MoveTodo), " "); got != tt.want {
+				t.Errorf("returned order = %s, want %s", got, tt.want)
+			}
+			todos, _ := store.List()
+			if got := strings.Join(todoIDs(todos), " "); got != tt.want {
+				t.Errorf("stored order = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoveTodoUnknown(t *testing.T) {
+	resetTodos(t, Todo{ID: "a"}, Todo{ID: "b"})
+	if code := errorCode(t, execute(t, `mutation{moveTodo(id:"nope",toIndex:0){id}}`)); code != ErrCodeNotFound {
+		t.Errorf("code = %q, want %s", code, ErrCodeNotFound)
+	}
+	todos, _ := store.List()
+	if got := strings.Join(todoIDs(todos), " "); got != "a b" {
+		t.Errorf("order = %s, want it unchanged", got)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPointsTarget(t *testing.T) {
+	at := func(day, hour int) *time.Time {
+		completed := time.Date(2026, 10, day, hour, 0, 0, 0, time.UTC)
+		return &completed
+	}
+	resetTodos(t,
+		Todo{ID: "a", Done: true, CompletedAt: at(5, 0), StoryPoints: 8},
+		Todo{ID: "b", Done: true, CompletedAt: at(10, 12), StoryPoints: 5},
+		Todo{ID: "c", Done: true, CompletedAt: at(19, 0), StoryPoints: 13},
+		Todo{ID: "d", Done: false, StoryPoints: 21},
+		Todo{ID: "e", Done: true, CompletedAt: at(1, 0), StoryPoints: 3},
+	)
+	saved := pointsTarget
+	pointsTarget = 13
+	t.Cleanup(func() { pointsTarget = saved })
+
+	tests := []struct {
+		name     string
+		from, to string
+		total    int
+		met      bool
+	}{
+		// from is inclusive, to exclusive: a counts, c doesn't
+		{"sprint", "2026-10-05T00:00:00Z", "2026-10-19T00:00:00Z", 13, true},
+		{"short of the target", "2026-10-06T00:00:00Z", "2026-10-19T00:00:00Z", 5, false},
+		{"nothing completed", "2026-11-01T00:00:00Z", "2026-11-15T00:00:00Z", 0, false},
+		{"empty window", "2026-10-05T00:00:00Z", "2026-10-05T00:00:00Z", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data struct{ PointsTarget PointsTargetResult }
+			mustExecute(t, `{pointsTarget(from:"`+tt.from+`",to:"`+tt.to+`"){total,target,met}}`, &data)
+			want := PointsTargetResult{Total: tt.total, Target: 13, Met: tt.met}
+			if data.PointsTarget != want {
+				t.Errorf("pointsTarget = %+v, want %+v", data.PointsTarget, want)
+			}
+		})
+	}
+
+	if code := errorCode(t, execute(t, `{pointsTarget(from:"2026-10-19T00:00:00Z",to:"2026-10-05T00:00:00Z"){met}}`)); code != ErrCodeValidationError {
+		t.Errorf("reversed window: code = %q, want %s", code, ErrCodeValidationError)
+	}
+}
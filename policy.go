@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// deniedFields names root query, mutation and subscription fields that are
+// left out of the schema, e.g. to disable destructive mutations in a demo.
+// It is read by BuildSchema, so set it before building the schema.
+var deniedFields = map[string]bool{}
+
+// parseFieldList parses a comma separated list of field names.
+func parseFieldList(list string) map[string]bool {
+	fields := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// withoutDeniedFields drops the denied fields from a root type's fields,
+// adding their names to removed. Queries selecting them then fail validation
+// like any unknown field.
+func withoutDeniedFields(removed map[string]bool, fields graphql.Fields) graphql.Fields {
+	for name := range fields {
+		if deniedFields[name] {
+			delete(fields, name)
+			removed[name] = true
+		}
+	}
+	return fields
+}
+
+// checkDeniedFields reports denied names that are no root field, given the
+// ones withoutDeniedFields removed, so a typo doesn't leave a field enabled.
+func checkDeniedFields(removed map[string]bool) error {
+	unknown := []string{}
+	for name := range deniedFields {
+		if !removed[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("deny fields: unknown root field %s", strings.Join(unknown, ", "))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+// denyFields sets deniedFields from list for the rest of the test.
+func denyFields(t *testing.T, list string) {
+	t.Helper()
+	deniedFields = parseFieldList(list)
+	t.Cleanup(func() { deniedFields = map[string]bool{} })
+}
+
+// rootFieldNames returns the field names of a root type of the undenied schema.
+func rootFieldNames(t *testing.T, root func(*graphql.Schema) *graphql.Object) []string {
+	t.Helper()
+	schema, err := BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for name := range root(&schema).Fields() {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestDenyFields(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "keep me"})
+	denyFields(t, " archiveTodo , clearCompleted")
+
+	result := execute(t, `mutation{archiveTodo(id:"a"){id}}`)
+	if !result.HasErrors() || !strings.Contains(result.Errors[0].Message, `Cannot query field "archiveTodo"`) {
+		t.Fatalf("archiveTodo errors = %v, want a validation error", result.Errors)
+	}
+	var data struct{ TodoList []Todo }
+	mustExecute(t, `{todoList{id}}`, &data)
+	if len(data.TodoList) != 1 {
+		t.Errorf("todoList = %v, want the todo still listed", data.TodoList)
+	}
+	var created struct{ CreateTodo Todo }
+	mustExecute(t, `mutation{createTodo(text:"allowed",task:"t"){id}}`, &created)
+}
+
+func TestDenyFieldsUnknown(t *testing.T) {
+	denyFields(t, "archiveTodo,archiveTodos,nope")
+	_, err := BuildSchema()
+	if err == nil || !strings.Contains(err.Error(), "unknown root field archiveTodos, nope") {
+		t.Errorf("err = %v, want the unknown names", err)
+	}
+}
+
+func TestDenyFieldsAllQueries(t *testing.T) {
+	denyFields(t, strings.Join(rootFieldNames(t, (*graphql.Schema).QueryType), ","))
+	_, err := BuildSchema()
+	if err == nil || !strings.Contains(err.Error(), "every RootQuery field is denied") {
+		t.Errorf("err = %v, want every RootQuery field denied", err)
+	}
+}
+
+func TestDenyFieldsAllMutations(t *testing.T) {
+	resetTodos(t)
+	denyFields(t, strings.Join(rootFieldNames(t, (*graphql.Schema).MutationType), ","))
+	schema, err := BuildSchema()
+	if err != nil {
+		t.Fatalf("build schema: %v", err)
+	}
+	if schema.MutationType() != nil {
+		t.Errorf("mutation type = %v, want none", schema.MutationType())
+	}
+	if result := execute(t, `mutation{createTodo(text:"x",task:"t"){id}}`); !result.HasErrors() {
+		t.Error("createTodo succeeded with every mutation denied")
+	}
+	var data struct{ TodoList []Todo }
+	mustExecute(t, `{todoList{id}}`, &data)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/handler"
+)
+
+func TestPrettyToggle(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "format me"})
+	schema, err := BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := PrettyToggle(
+		handler.New(&handler.Config{Schema: &schema, Pretty: true}),
+		handler.New(&handler.Config{Schema: &schema, Pretty: false}),
+	)
+	query := "/graphql?query=" + url.QueryEscape(`{todoList{id,text}}`)
+	tests := []struct {
+		name     string
+		target   string
+		header   string
+		indented bool
+	}{
+		{"default", query, "", true},
+		{"pretty=false", query + "&pretty=false", "", false},
+		{"pretty=0", query + "&pretty=0", "", false},
+		{"pretty=true", query + "&pretty=true", "", true},
+		{"header", query, "false", false},
+		{"query wins over header", query + "&pretty=true", "false", true},
+		{"unparsable", query + "&pretty=maybe", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				r.Header.Set("X-Pretty", tt.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			body := w.Body.String()
+			if !strings.Contains(body, "format me") {
+				t.Fatalf("body = %s", body)
+			}
+			if indented := strings.Contains(strings.TrimSpace(body), "\n"); indented != tt.indented {
+				t.Errorf("indented = %v, want %v: %s", indented, tt.indented, body)
+			}
+		})
+	}
+}
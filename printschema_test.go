@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// introspectedType is a type of the introspection result, with its field names.
+type introspectedType struct {
+	Name   string
+	Fields []struct{ Name string }
+}
+
+func TestIntrospectionListsTodo(t *testing.T) {
+	var data struct {
+		Schema struct {
+			QueryType struct{ Name string }
+			Types     []introspectedType
+		} `json:"__schema"`
+	}
+	mustExecute(t, introspectionQuery, &data)
+	if data.Schema.QueryType.Name != "RootQuery" {
+		t.Errorf("query type = %q, want RootQuery", data.Schema.QueryType.Name)
+	}
+	assertTodoType(t, data.Schema.Types)
+}
+
+func TestPrintSchema(t *testing.T) {
+	schema, err := BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := PrintSchema(&out, schema); err != nil {
+		t.Fatal(err)
+	}
+	var printed struct {
+		Data struct {
+			Schema struct{ Types []introspectedType } `json:"__schema"`
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &printed); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	assertTodoType(t, printed.Data.Schema.Types)
+}
+
+// assertTodoType checks that types has Todo with its main fields.
+func assertTodoType(t *testing.T, types []introspectedType) {
+	t.Helper()
+	for _, typ := range types {
+		if typ.Name != "Todo" {
+			continue
+		}
+		fields := map[string]bool{}
+		for _, f := range typ.Fields {
+			fields[f.Name] = true
+		}
+		for _, name := range []string{"id", "text", "done", "tags", "createdAt", "updatedAt", "version"} {
+			if !fields[name] {
+				t.Errorf("Todo has no field %s", name)
+			}
+		}
+		return
+	}
+	t.Error("no Todo type in the introspection result")
+}
@@ -0,0 +1,51 @@
+package main
+
+import "sync"
+
+// TodoBroker fans out changed todos to the live subscriptions.
+type TodoBroker struct {
+	mu          sync.Mutex
+	subscribers []chan interface{}
+}
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before further events are dropped for it.
+const subscriberBuffer = 16
+
+// Subscribe registers a new subscriber and returns the channel its events arrive on.
+func (b *TodoBroker) Subscribe() chan interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan interface{}, subscriberBuffer)
+	b.subscribers = append(b.subscribers, ch)
+	return ch
+}
+
+// Unsubscribe removes ch and closes it, which ends the subscription.
+func (b *TodoBroker) Unsubscribe(ch chan interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, sub := range b.subscribers {
+		if sub == ch {
+			b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+// Publish sends todo to every subscriber without blocking the caller;
+// a subscriber whose buffer is full misses the event.
+func (b *TodoBroker) Publish(todo Todo) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- todo:
+		default:
+		}
+	}
+}
+
+// todoChanges is fed by the mutations and read by the todoChanged subscription.
+var todoChanges = &TodoBroker{}
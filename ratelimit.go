@@ -0,0 +1,107 @@
+package main
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// IPRateLimiter hands out a token bucket per client IP.
+type IPRateLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu      sync.Mutex
+	buckets map[string]*ipBucket
+}
+
+// ipBucket is the token bucket of one IP and when it was last used.
+type ipBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewIPRateLimiter allows each IP perSecond requests on average, in bursts of
+// up to burst requests.
+func NewIPRateLimiter(perSecond float64, burst int) *IPRateLimiter {
+	return &IPRateLimiter{limit: rate.Limit(perSecond), burst: burst, buckets: map[string]*ipBucket{}}
+}
+
+// reserve takes a token from the bucket of ip. It returns how long the client
+// has to wait when the bucket is empty, or 0 when the request may proceed.
+func (l *IPRateLimiter) reserve(ip string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	bucket, ok := l.buckets[ip]
+	if !ok {
+		bucket = &ipBucket{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.buckets[ip] = bucket
+	}
+	bucket.lastSeen = now
+
+	reservation := bucket.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		// a burst of 0 never admits a request; ask the client to back off a second
+		return time.Second
+	}
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		// the request is refused, so give the token back
+		reservation.CancelAt(now)
+	}
+	return delay
+}
+
+// Reap forgets the buckets of IPs not seen for idle. A forgotten IP starts over
+// with a full bucket, which it would have refilled to by then anyway.
+func (l *IPRateLimiter) Reap(now time.Time, idle time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ip, bucket := range l.buckets {
+		if now.Sub(bucket.lastSeen) > idle {
+			delete(l.buckets, ip)
+		}
+	}
+}
+
+// StartReaper reaps buckets idle for longer than idle, checking every interval,
+// until the process exits.
+func (l *IPRateLimiter) StartReaper(interval, idle time.Duration) {
+	go func() {
+		for now := range time.Tick(interval) {
+			l.Reap(now, idle)
+		}
+	}()
+}
+
+// clientIP returns the IP of the peer of r. Forwarding headers are not
+// trusted, so behind a proxy all clients share the proxy's bucket.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RateLimit answers 429 Too Many Requests, with a Retry-After header in whole
+// seconds, to clients that exceed their bucket in limiter. A nil limiter
+// disables the check.
+func RateLimit(limiter *IPRateLimiter, next http.Handler) http.Handler {
+	if limiter == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if delay := limiter.reserve(clientIP(r), time.Now()); delay > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			writeGraphQLError(w, http.StatusTooManyRequests, "too many requests, retry later")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	// slow enough for no token to come back during the test
+	h := RateLimit(NewIPRateLimiter(0.01, 5), ok)
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/graphql", nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	passed, limited := 0, 0
+	for i := 0; i < 20; i++ {
+		// the port changes per connection, the bucket is per IP
+		w := send("203.0.113.7:" + strconv.Itoa(40000+i))
+		switch w.Code {
+		case http.StatusOK:
+			passed++
+		case http.StatusTooManyRequests:
+			limited++
+			if retry, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || retry < 1 {
+				t.Fatalf("Retry-After = %q, want whole seconds", w.Header().Get("Retry-After"))
+			}
+		default:
+			t.Fatalf("status = %d", w.Code)
+		}
+	}
+	if passed != 5 || limited != 15 {
+		t.Errorf("passed %d and limited %d of 20, want the burst of 5 to pass", passed, limited)
+	}
+
+	if w := send("198.51.100.1:1234"); w.Code != http.StatusOK {
+		t.Errorf("other IP: status = %d, want it unaffected", w.Code)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h := RateLimit(nil, ok)
+	for i := 0; i < 100; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d", i, w.Code)
+		}
+	}
+}
+
+func TestIPRateLimiterRefillAndReap(t *testing.T) {
+	limiter := NewIPRateLimiter(2, 1)
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	if delay := limiter.reserve("a", start); delay != 0 {
+		t.Fatalf("first request delayed %v", delay)
+	}
+	if delay := limiter.reserve("a", start); delay <= 0 || delay > 500*time.Millisecond {
+		t.Errorf("second request delay = %v, want up to half a second at 2/s", delay)
+	}
+	// the refused request gave its token back, so half a second later one is there
+	if delay := limiter.reserve("a", start.Add(500*time.Millisecond)); delay != 0 {
+		t.Errorf("after refilling: delay = %v", delay)
+	}
+
+	limiter.reserve("b", start.Add(time.Minute))
+	limiter.Reap(start.Add(time.Minute+time.Second), 30*time.Second)
+	if _, ok := limiter.buckets["a"]; ok {
+		t.Error("idle bucket a was not reaped")
+	}
+	if _, ok := limiter.buckets["b"]; !ok {
+		t.Error("recently seen bucket b was reaped")
+	}
+
+	zero := NewIPRateLimiter(1, 0)
+	if delay := zero.reserve("a", start); delay != time.Second {
+		t.Errorf("burst 0: delay = %v, want a second", delay)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReopenTodo(t *testing.T) {
+	then := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	resetTodos(t, Todo{ID: "a", Text: "finished", Done: true, CompletedAt: &then, UpdatedAt: then, Version: 2})
+
+	var data struct{ ReopenTodo Todo }
+	mustExecute(t, `mutation{reopenTodo(id:"a"){id,done,completedAt,updatedAt,version}}`, &data)
+	todo := data.ReopenTodo
+	if todo.Done || todo.CompletedAt != nil || todo.Version != 3 || !todo.UpdatedAt.After(then) {
+		t.Errorf("reopened = %+v, want not done, no completedAt, version 3 and a new updatedAt", todo)
+	}
+	if stored, _, _ := store.Get("a"); stored.Done || stored.CompletedAt != nil {
+		t.Errorf("stored = %+v, want it reopened", stored)
+	}
+}
+
+func TestReopenTodoRejected(t *testing.T) {
+	resetTodos(t, Todo{ID: "open", Text: "still open", Version: 1})
+	if code := errorCode(t, execute(t, `mutation{reopenTodo(id:"open"){id}}`)); code != ErrCodeConflict {
+		t.Errorf("already open: code = %q, want %s", code, ErrCodeConflict)
+	}
+	if todo, _, _ := store.Get("open"); todo.Version != 1 {
+		t.Errorf("version = %d, want the no-op to change nothing", todo.Version)
+	}
+	if code := errorCode(t, execute(t, `mutation{reopenTodo(id:"nope"){id}}`)); code != ErrCodeNotFound {
+		t.Errorf("unknown id: code = %q, want %s", code, ErrCodeNotFound)
+	}
+}
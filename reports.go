@@ -0,0 +1,131 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// HourCount is the number of todos created during one hour of the day.
+type HourCount struct {
+	Hour  int `json:"hour"`
+	Count int `json:"count"`
+}
+
+// CreationByHour buckets todos by the hour of day, in loc, at which they were created.
+// All 24 hours are returned, in order, including those without any todo.
+func CreationByHour(todos []Todo, loc *time.Location) []HourCount {
+	histogram := make([]HourCount, 24)
+	for hour := range histogram {
+		histogram[hour].Hour = hour
+	}
+	for _, todo := range todos {
+		histogram[todo.CreatedAt.In(loc).Hour()].Count++
+	}
+	return histogram
+}
+
+// DayCount is the number of todos due on one calendar day.
+type DayCount struct {
+	Day   string `json:"day"`
+	Count int    `json:"count"`
+}
+
+// BusiestUpcomingDay returns the calendar day, in loc, on which the most todos
+// are due, ignoring due dates before now. Ties go to the earliest day.
+// It returns nil when no todo is due in the future.
+func BusiestUpcomingDay(todos []Todo, now time.Time, loc *time.Location) *DayCount {
+	counts := map[string]int{}
+	for _, todo := range todos {
+		if todo.DueDate == nil || todo.DueDate.Before(now) {
+			continue
+		}
+		counts[todo.DueDate.In(loc).Format("2006-01-02")]++
+	}
+
+	var busiest *DayCount
+	for day, count := range counts {
+		if busiest == nil || count > busiest.Count || (count == busiest.Count && day < busiest.Day) {
+			busiest = &DayCount{Day: day, Count: count}
+		}
+	}
+	return busiest
+}
+
+// WeekPoints is the number of story points completed in one week.
+type WeekPoints struct {
+	// WeekStart is the Monday the week starts on, as YYYY-MM-DD.
+	WeekStart string `json:"weekStart"`
+	Points    int    `json:"points"`
+}
+
+// weekStart returns midnight of the Monday starting the week t falls in.
+func weekStart(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, t.Location())
+}
+
+// Velocity sums the story points of done todos per week of completion, in loc.
+// Weeks are sorted oldest first; weeks without completed points are left out.
+func Velocity(todos []Todo, loc *time.Location) []WeekPoints {
+	points := map[string]int{}
+	for _, todo := range todos {
+		if !todo.Done || todo.CompletedAt == nil || todo.StoryPoints == 0 {
+			continue
+		}
+		points[weekStart(todo.CompletedAt.In(loc)).Format("2006-01-02")] += todo.StoryPoints
+	}
+
+	velocity := make([]WeekPoints, 0, len(points))
+	for week, p := range points {
+		velocity = append(velocity, WeekPoints{WeekStart: week, Points: p})
+	}
+	sort.Slice(velocity, func(i, j int) bool {
+		return velocity[i].WeekStart < velocity[j].WeekStart
+	})
+	return velocity
+}
+
+// pointsTarget is the number of completed story points a window should reach
+// for pointsTarget to report it as met.
+var pointsTarget = 20
+
+// PointsTargetResult compares the story points completed in a window with the target.
+type PointsTargetResult struct {
+	Total  int  `json:"total"`
+	Target int  `json:"target"`
+	Met    bool `json:"met"`
+}
+
+// CompletedPoints sums the story points of todos completed in [from, to).
+func CompletedPoints(todos []Todo, from, to time.Time) int {
+	total := 0
+	for _, todo := range todos {
+		if !todo.Done || todo.CompletedAt == nil {
+			continue
+		}
+		if !todo.CompletedAt.Before(from) && todo.CompletedAt.Before(to) {
+			total += todo.StoryPoints
+		}
+	}
+	return total
+}
+
+// staleAfter is how long a not done todo may go without an update before
+// staleTodos reports it.
+var staleAfter = 14 * 24 * time.Hour
+
+// StaleTodos returns the not done, unarchived todos last updated more than
+// threshold before now, least recently updated first.
+func StaleTodos(todos []Todo, now time.Time, threshold time.Duration) []Todo {
+	cutoff := now.Add(-threshold)
+	stale := []Todo{}
+	for _, todo := range todos {
+		if !todo.Done && !todo.Archived && todo.UpdatedAt.Before(cutoff) {
+			stale = append(stale, todo)
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].UpdatedAt.Before(stale[j].UpdatedAt)
+	})
+	return stale
+}
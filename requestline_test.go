@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestGuardRequestLine(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "guarded"})
+	h := GuardRequestLine(256, graphqlHandler(t))
+	short := "/graphql?query=" + url.QueryEscape(`{todoList{id}}`)
+	long := "/graphql?query=" + url.QueryEscape(`{todoList{id}}`) + "&pad=" + strings.Repeat("x", 256)
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{"PUT", http.MethodPut, short, "", http.StatusMethodNotAllowed},
+		{"DELETE", http.MethodDelete, short, "", http.StatusMethodNotAllowed},
+		{"over-length GET", http.MethodGet, long, "", http.StatusRequestURITooLong},
+		{"GET", http.MethodGet, short, "", http.StatusOK},
+		{"POST", http.MethodPost, "/graphql", `{"query":"{todoList{id}}"}`, http.StatusOK},
+		// only GET puts the query in the URL, a long POST URL is left alone
+		{"long POST URL", http.MethodPost, long, `{"query":"{todoList{id}}"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				r.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status == http.StatusMethodNotAllowed && w.Header().Get("Allow") != "GET, POST, OPTIONS" {
+				t.Errorf("Allow = %q", w.Header().Get("Allow"))
+			}
+			if tt.status == http.StatusOK && !strings.Contains(w.Body.String(), `"id":"a"`) {
+				t.Errorf("body = %s", w.Body)
+			}
+		})
+	}
+}
+
+func TestGuardRequestLineOptions(t *testing.T) {
+	mutation := url.QueryEscape(`mutation{createTodo(text:"sneaky",task:"t"){id}}`)
+	for name, creds := range map[string]Credentials{"auth off": nil, "auth on": {"s3cret": ""}} {
+		t.Run(name, func(t *testing.T) {
+			resetTodos(t)
+			h := GuardRequestLine(8192, BearerAuth(creds, graphqlHandler(t)))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/graphql?query="+mutation, nil))
+			if w.Code != http.StatusNoContent {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusNoContent)
+			}
+			if allow := w.Header().Get("Allow"); allow != "GET, POST, OPTIONS" {
+				t.Errorf("Allow = %q", allow)
+			}
+			if todos, _ := store.List(); len(todos) != 0 {
+				t.Errorf("todos = %v, OPTIONS must not run the query", todoIDs(todos))
+			}
+		})
+	}
+}
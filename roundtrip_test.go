@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	created := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	completed := created.Add(26 * time.Hour)
+	due := created.AddDate(0, 1, 0)
+	original := []Todo{
+		{ID: "todo_a", Text: "plain", CreatedAt: created, UpdatedAt: created, Version: 1},
+		{
+			ID: "todo_b", Text: "everything set", Task: "work", Done: true, CompletedAt: &completed,
+			Tags: []string{"x", "y"}, Assignee: "alice", DueDate: &due, StoryPoints: 5,
+			ExternalRef: "https://github.com/o/r/issues/1", Archived: true,
+			CreatedAt: created, UpdatedAt: completed, Version: 7,
+			Attachments: []Attachment{{ID: "att_1", Filename: "plan.pdf", Size: 10, ContentType: "application/pdf", AddedAt: created}},
+		},
+	}
+	resetTodos(t, original...)
+
+	var exported struct{ ExportTodos string }
+	mustExecute(t, `{exportTodos}`, &exported)
+
+	resetTodos(t)
+	var imported struct{ ImportTodos int }
+	mustExecute(t, importQuery(exported.ExportTodos), &imported)
+	if imported.ImportTodos != len(original) {
+		t.Fatalf("imported %d todos, want %d", imported.ImportTodos, len(original))
+	}
+
+	restored, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(original)
+	got, _ := json.Marshal(restored)
+	if string(got) != string(want) {
+		t.Errorf("after the round trip:\n got %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+func TestSafeResolveRecoversPanic(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"boom": &graphql.Field{
+					Type: graphql.String,
+					Resolve: safeResolve(func(params graphql.ResolveParams) (interface{}, error) {
+						var todos []Todo
+						return todos[0].Text, nil
+					}),
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := graphql.Do(graphql.Params{Schema: schema, RequestString: `{boom}`, Context: context.Background()})
+	if code := errorCode(t, result); code != ErrCodeInternal {
+		t.Errorf("code = %q, want %s", code, ErrCodeInternal)
+	}
+	if msg := result.Errors[0].Message; msg != "internal error" {
+		t.Errorf("message = %q, the panic must not reach the client", msg)
+	}
+	if !strings.Contains(logged.String(), "panic resolving boom") || !strings.Contains(logged.String(), "goroutine") {
+		t.Errorf("log = %q, want the panic and its stack", logged.String())
+	}
+}
+
+func TestLastTodoEmptyStore(t *testing.T) {
+	resetTodos(t)
+	var data struct{ LastTodo *Todo }
+	mustExecute(t, `{lastTodo{id}}`, &data)
+	if data.LastTodo != nil {
+		t.Errorf("lastTodo = %+v, want null", data.LastTodo)
+	}
+
+	resetTodos(t, Todo{ID: "a", Text: "first"}, Todo{ID: "b", Text: "second"})
+	mustExecute(t, `{lastTodo{id}}`, &data)
+	if data.LastTodo == nil || data.LastTodo.ID != "b" {
+		t.Errorf("lastTodo = %+v, want b", data.LastTodo)
+	}
+}
@@ -0,0 +1,1501 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// BuildSchema assembles the GraphQL schema for the todo API.
+// The resolvers operate on the package level store.
+func BuildSchema() (graphql.Schema, error) {
+	// root fields left out because of deniedFields
+	denied := map[string]bool{}
+
+	attachmentType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Attachment",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.String,
+			},
+			"filename": &graphql.Field{
+				Type: graphql.String,
+			},
+			"size": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "Size in bytes",
+			},
+			"contentType": &graphql.Field{
+				Type: graphql.String,
+			},
+			"addedAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+		},
+	})
+
+	// define custom GraphQL ObjectType `todoType` for our Golang struct `Todo`
+	// Note that
+	// - the fields in our todoType maps with the json tags for the fields in our struct
+	// - the field type matches the field type in our struct
+	todoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Todo",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.String,
+			},
+			"text": &graphql.Field{
+				Type: graphql.String,
+			},
+			"done": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"task": &graphql.Field{
+				Type:              graphql.String,
+				DeprecationReason: "Use tags to categorize todos",
+			},
+			"tags": &graphql.Field{
+				Type: graphql.NewList(graphql.String),
+			},
+			"assignee": &graphql.Field{
+				Type: graphql.String,
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"updatedAt": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "When the todo was created or last changed",
+			},
+			"dueDate": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"completedAt": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "When the todo was last marked done; null while it is not done",
+			},
+			"attachments": &graphql.Field{
+				Type: graphql.NewList(attachmentType),
+			},
+			"version": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "Incremented on every change, for use as updateTodo's expectedVersion",
+			},
+			"storyPoints": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "Estimated effort; 0 while not estimated",
+			},
+			"externalRef": &graphql.Field{
+				Type:        graphql.String,
+				Description: "URL of a linked ticket or issue in another tracker; empty when not linked",
+			},
+			"archived": &graphql.Field{
+				Type:        graphql.Boolean,
+				Description: "Archived todos are left out of todoList unless includeArchived is set",
+			},
+			"attachmentCount": &graphql.Field{
+				Type: graphql.Int,
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					todo, _ := params.Source.(Todo)
+					return len(todo.Attachments), nil
+				},
+			},
+		},
+	})
+
+	// result of mutations that work on a list of ids
+	todoBatchResultType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoBatchResult",
+		Fields: graphql.Fields{
+			"todos": &graphql.Field{
+				Type:        graphql.NewList(todoType),
+				Description: "Todos after the change",
+			},
+			"notFound": &graphql.Field{
+				Type:        graphql.NewList(graphql.String),
+				Description: "Requested ids that matched no todo",
+			},
+		},
+	})
+
+	snapshotType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Snapshot",
+		Fields: graphql.Fields{
+			"label": &graphql.Field{
+				Type: graphql.String,
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"todoCount": &graphql.Field{
+				Type: graphql.Int,
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					snapshot, _ := params.Source.(Snapshot)
+					return len(snapshot.Todos), nil
+				},
+			},
+		},
+	})
+
+	idMappingType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "IDMapping",
+		Fields: graphql.Fields{
+			"oldId": &graphql.Field{
+				Type: graphql.String,
+			},
+			"newId": &graphql.Field{
+				Type: graphql.String,
+			},
+		},
+	})
+
+	trelloImportResultType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TrelloImportResult",
+		Fields: graphql.Fields{
+			"imported": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"ids": &graphql.Field{
+				Type:        graphql.NewList(idMappingType),
+				Description: "Trello card ids and the ids of the todos created for them",
+			},
+		},
+	})
+
+	exportFormatEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "ExportFormat",
+		Values: graphql.EnumValueConfigMap{
+			ExportJSON: &graphql.EnumValueConfig{
+				Value:       ExportJSON,
+				Description: "JSON array of todos",
+			},
+			ExportJSONL: &graphql.EnumValueConfig{
+				Value:       ExportJSONL,
+				Description: "One JSON todo per line",
+			},
+			ExportCSV: &graphql.EnumValueConfig{
+				Value:       ExportCSV,
+				Description: "CSV with a header row",
+			},
+			ExportICS: &graphql.EnumValueConfig{
+				Value:       ExportICS,
+				Description: "iCalendar with one VTODO per todo",
+			},
+		},
+	})
+
+	hourCountType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "HourCount",
+		Fields: graphql.Fields{
+			"hour": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"count": &graphql.Field{
+				Type: graphql.Int,
+			},
+		},
+	})
+
+	dayCountType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "DayCount",
+		Fields: graphql.Fields{
+			"day": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Calendar day as YYYY-MM-DD",
+			},
+			"count": &graphql.Field{
+				Type: graphql.Int,
+			},
+		},
+	})
+
+	// input for creating todos in bulk, the same fields createTodo takes as arguments
+	todoInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "TodoInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"text": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"task": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"tags": &graphql.InputObjectFieldConfig{
+				Type: graphql.NewList(graphql.NewNonNull(graphql.String)),
+			},
+			"assignee": &graphql.InputObjectFieldConfig{
+				Type: graphql.String,
+			},
+			"dueDate": &graphql.InputObjectFieldConfig{
+				Type: graphql.DateTime,
+			},
+		},
+	})
+
+	weekPointsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "WeekPoints",
+		Fields: graphql.Fields{
+			"weekStart": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Monday the week starts on, as YYYY-MM-DD",
+			},
+			"points": &graphql.Field{
+				Type: graphql.Int,
+			},
+		},
+	})
+
+	pointsTargetResultType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PointsTargetResult",
+		Fields: graphql.Fields{
+			"total": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "Story points completed in the window",
+			},
+			"target": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"met": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+		},
+	})
+
+	// root mutation
+	rootMutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "RootMutation",
+		Fields: withoutSnapshotMutations(withoutDeniedFields(denied, graphql.Fields{
+			"createTodo": &graphql.Field{
+				Type: todoType, // the return type for this field
+				Args: graphql.FieldConfigArgument{
+					"text": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"task": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"tags": &graphql.ArgumentConfig{
+						Type: graphql.NewList(graphql.NewNonNull(graphql.String)),
+					},
+					"assignee": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"dueDate": &graphql.ArgumentConfig{
+						Type: graphql.DateTime,
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+
+					// marshall and cast the argument values
+					// perform mutation operation here
+					// for e.g. create a Todo and save to DB.
+					newTodo, err := NewTodoFromArgs(params.Args, time.Now().UTC())
+					if err != nil {
+						return nil, err
+					}
+					fmt.Println("------------------> ", newTodo)
+					// return the new Todo object that we supposedly save to DB
+					// Note here that
+					// - we are returning a `Todo` struct instance here
+					// - we previously specified the return Type to be `todoType`
+					// - `Todo` struct maps to `todoType`, as defined in `todoType` ObjectConfig`
+					todoMu.Lock()
+					err = store.Add(newTodo)
+					todoMu.Unlock()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					todoChanges.Publish(newTodo)
+					return newTodo, nil
+				},
+			},
+
+			"createTodos": &graphql.Field{
+				Type:        graphql.NewList(todoType),
+				Description: "Create several todos at once, returned in input order; one invalid input fails the whole batch",
+				Args: graphql.FieldConfigArgument{
+					"inputs": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(todoInputType))),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					inputs, _ := params.Args["inputs"].([]interface{})
+					now := time.Now().UTC()
+					// build every todo first so nothing is stored when an input is invalid
+					todos := make([]Todo, 0, len(inputs))
+					for i, input := range inputs {
+						args, _ := input.(map[string]interface{})
+						todo, err := NewTodoFromArgs(args, now)
+						if err != nil {
+							return nil, NewCodedError(ErrCodeValidationError, "inputs[%d]: %v", i, err)
+						}
+						todos = append(todos, todo)
+					}
+
+					todoMu.Lock()
+					err := store.Add(todos...)
+					todoMu.Unlock()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					for _, todo := range todos {
+						todoChanges.Publish(todo)
+					}
+					return todos, nil
+				},
+			},
+
+			//update opration of TODO
+			"updateTodo": &graphql.Field{
+				Type:        todoType, // the return type for this field
+				Description: "Update existing todo, mark it done or not done; only the assignee may mark an assigned todo done",
+				Args: graphql.FieldConfigArgument{
+					"done": &graphql.ArgumentConfig{
+						Type: graphql.Boolean,
+					},
+					"id": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"expectedVersion": &graphql.ArgumentConfig{
+						Type:        graphql.Int,
+						Description: "Only update if the todo is still at this version, otherwise fail with CONFLICT",
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					// marshall and cast the argument value
+					done, _ := params.Args["done"].(bool)
+					id, _ := params.Args["id"].(string)
+					expectedVersion, checkVersion := params.Args["expectedVersion"].(int)
+
+					todoMu.Lock()
+					defer todoMu.Unlock()
+					// Look up the todo with id and change the done variable
+					todo, ok, err := store.Get(id)
+					if err != nil {
+						return nil, storeError(err)
+					}
+					if !ok {
+						return nil, todoNotFound(id)
+					}
+					if checkVersion && todo.Version != expectedVersion {
+						return nil, NewCodedError(ErrCodeConflict, "todo %q is at version %d, not %d", id, todo.Version, expectedVersion)
+					}
+					if done && !mayComplete(params.Context, todo) {
+						return nil, NewCodedError(ErrCodeForbidden, "only %q may mark todo %q done", todo.Assignee, id)
+					}
+					now := time.Now().UTC()
+					completed := todo.setDone(done, now)
+					todo.touch(now)
+					if _, err := store.Update(todo); err != nil {
+						return nil, storeError(err)
+					}
+					if completed {
+						completionDigest.Record(todo, now)
+					}
+					todoChanges.Publish(todo)
+					// Return affected todo
+					return todo, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query=mutation+M{reopenTodo(id:"a"){id,done,completedAt}}'
+			*/
+			"reopenTodo": &graphql.Field{
+				Type:        todoType,
+				Description: "Mark a done todo not done again; fails with CONFLICT when it is not done",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					id, _ := params.Args["id"].(string)
+
+					todoMu.Lock()
+					defer todoMu.Unlock()
+					todo, ok, err := store.Get(id)
+					if err != nil {
+						return nil, storeError(err)
+					}
+					if !ok {
+						return nil, todoNotFound(id)
+					}
+					if !todo.Done {
+						return nil, NewCodedError(ErrCodeConflict, "todo %q is not done", id)
+					}
+					now := time.Now().UTC()
+					todo.setDone(false, now)
+					todo.touch(now)
+					if _, err := store.Update(todo); err != nil {
+						return nil, storeError(err)
+					}
+					todoChanges.Publish(todo)
+					return todo, nil
+				},
+			},
+
+			"duplicateTodo": &graphql.Field{
+				Type:        todoType,
+				Description: "Copy a todo into a new, not done and unarchived todo without its external ref",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					id, _ := params.Args["id"].(string)
+
+					todoMu.Lock()
+					defer todoMu.Unlock()
+					clone, ok, err := store.Get(id)
+					if err != nil {
+						return nil, storeError(err)
+					}
+					if !ok {
+						return nil, todoNotFound(id)
+					}
+					now := time.Now().UTC()
+					clone.ID = NewID("todo")
+					clone.setDone(false, now)
+					clone.CreatedAt = now
+					clone.UpdatedAt = now
+					clone.Version = 1
+					// the copy is a new, active todo, not a second link to the same issue
+					clone.Archived = false
+					clone.ExternalRef = ""
+					for i := range clone.Attachments {
+						clone.Attachments[i].ID = NewID("attachment")
+					}
+					if err := store.Add(clone); err != nil {
+						return nil, storeError(err)
+					}
+					todoChanges.Publish(clone)
+					return clone, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query=mutation+M{addAttachment(todoId:"a",filename:"plan.pdf",size:1024,contentType:"application/pdf"){id,attachmentCount}}'
+			*/
+			"addAttachment": &graphql.Field{
+				Type:        todoType,
+				Description: "Attach file metadata to a todo, returning the updated todo",
+				Args: graphql.FieldConfigArgument{
+					"todoId": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"filename": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"size": &graphql.ArgumentConfig{
+						Type:        graphql.NewNonNull(graphql.Int),
+						Description: "Size in bytes",
+					},
+					"contentType": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					id, _ := params.Args["todoId"].(string)
+					filename, _ := params.Args["filename"].(string)
+					size, _ := params.Args["size"].(int)
+					contentType, _ := params.Args["contentType"].(string)
+
+					now := time.Now().UTC()
+					attachment, err := NewAttachment(filename, size, contentType, now)
+					if err != nil {
+						return nil, err
+					}
+
+					todoMu.Lock()
+					defer todoMu.Unlock()
+					todo, ok, err := store.Get(id)
+					if err != nil {
+						return nil, storeError(err)
+					}
+					if !ok {
+						return nil, todoNotFound(id)
+					}
+					todo.Attachments = append(todo.Attachments, attachment)
+					todo.touch(now)
+					if _, err := store.Update(todo); err != nil {
+						return nil, storeError(err)
+					}
+					todoChanges.Publish(todo)
+					return todo, nil
+				},
+			},
+
+			"setStoryPoints": &graphql.Field{
+				Type:        todoType,
+				Description: "Estimate a todo's effort in story points",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"points": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.Int),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					id, _ := params.Args["id"].(string)
+					points, _ := params.Args["points"].(int)
+					if !validStoryPoints(points) {
+						return nil, NewCodedError(ErrCodeValidationError, "%d is not an allowed story point value, use one of %v", points, allowedStoryPoints)
+					}
+
+					todoMu.Lock()
+					defer todoMu.Unlock()
+					todo, ok, err := store.Get(id)
+					if err != nil {
+						return nil, storeError(err)
+					}
+					if !ok {
+						return nil, todoNotFound(id)
+					}
+					todo.StoryPoints = points
+					todo.touch(time.Now().UTC())
+					if _, err := store.Update(todo); err != nil {
+						return nil, storeError(err)
+					}
+					todoChanges.Publish(todo)
+					return todo, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query=mutation+M{setExternalRef(id:"a",url:"https://github.com/imran-mind/GoGraphQL/issues/1"){id,externalRef}}'
+			*/
+			"setExternalRef": &graphql.Field{
+				Type:        todoType,
+				Description: "Link a todo to an external ticket or issue; an empty url removes the link",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"url": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					id, _ := params.Args["id"].(string)
+					url, _ := params.Args["url"].(string)
+					ref, err := ParseExternalRef(url)
+					if err != nil {
+						return nil, err
+					}
+
+					todoMu.Lock()
+					defer todoMu.Unlock()
+					todo, ok, err := store.Get(id)
+					if err != nil {
+						return nil, storeError(err)
+					}
+					if !ok {
+						return nil, todoNotFound(id)
+					}
+					todo.ExternalRef = ref
+					todo.touch(time.Now().UTC())
+					if _, err := store.Update(todo); err != nil {
+						return nil, storeError(err)
+					}
+					todoChanges.Publish(todo)
+					return todo, nil
+				},
+			},
+
+			"completeAllTodos": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "Mark every todo the caller may complete done, returning how many were not done before",
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					todoMu.Lock()
+					defer todoMu.Unlock()
+					todos, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					changed := 0
+					now := time.Now().UTC()
+					for _, todo := range todos {
+						if !todo.Done && mayComplete(params.Context, todo) {
+							todo.setDone(true, now)
+							todo.touch(now)
+							if _, err := store.Update(todo); err != nil {
+								return nil, storeError(err)
+							}
+							completionDigest.Record(todo, now)
+							todoChanges.Publish(todo)
+							changed++
+						}
+					}
+					return changed, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query=mutation+M{moveTodo(id:"c",toIndex:0){id}}'
+			*/
+			"moveTodo": &graphql.Field{
+				Type:        graphql.NewList(todoType),
+				Description: "Move a todo to another position in the list, returning the reordered list",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"toIndex": &graphql.ArgumentConfig{
+						Type:        graphql.NewNonNull(graphql.Int),
+						Description: "New position; out of range values move the todo to the front or the back",
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					id, _ := params.Args["id"].(string)
+					toIndex, _ := params.Args["toIndex"].(int)
+
+					todoMu.Lock()
+					defer todoMu.Unlock()
+					todos, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					from := -1
+					for i := range todos {
+						if sameTodoID(todos[i].ID, id) {
+							from = i
+							break
+						}
+					}
+					if from < 0 {
+						return nil, todoNotFound(id)
+					}
+					if toIndex < 0 {
+						toIndex = 0
+					}
+					if toIndex > len(todos)-1 {
+						toIndex = len(todos) - 1
+					}
+
+					todo := todos[from]
+					reordered := make([]Todo, 0, len(todos))
+					reordered = append(reordered, todos[:from]...)
+					reordered = append(reordered, todos[from+1:]...)
+					reordered = append(reordered[:toIndex], append([]Todo{todo}, reordered[toIndex:]...)...)
+					if err := store.Replace(reordered); err != nil {
+						return nil, storeError(err)
+					}
+					return reordered, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query=mutation+M{archiveTodo(id:"a"){id,archived}}'
+			*/
+			"archiveTodo": &graphql.Field{
+				Type:        todoType,
+				Description: "Archive a todo, hiding it from todoList without deleting it",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					id, _ := params.Args["id"].(string)
+
+					todoMu.Lock()
+					defer todoMu.Unlock()
+					todo, ok, err := store.Get(id)
+					if err != nil {
+						return nil, storeError(err)
+					}
+					if !ok {
+						return nil, todoNotFound(id)
+					}
+					todo.Archived = true
+					todo.touch(time.Now().UTC())
+					if _, err := store.Update(todo); err != nil {
+						return nil, storeError(err)
+					}
+					todoChanges.Publish(todo)
+					return todo, nil
+				},
+			},
+
+			"clearCompleted": &graphql.Field{
+				Type:        graphql.NewList(todoType),
+				Description: "Delete every todo that is done, returning the remaining todos",
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					todoMu.Lock()
+					defer todoMu.Unlock()
+					todos, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					remaining := []Todo{}
+					for _, todo := range todos {
+						if !todo.Done {
+							remaining = append(remaining, todo)
+							continue
+						}
+						if _, err := store.Delete(todo.ID); err != nil {
+							return nil, storeError(err)
+						}
+						todoChanges.Publish(todo)
+					}
+					return remaining, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query=mutation+M{createSnapshot(label:"demo"){label,createdAt,todoCount}}'
+			*/
+			"createSnapshot": &graphql.Field{
+				Type:        snapshotType,
+				Description: "Admin: save the whole todo list under a label to restore later",
+				Args: graphql.FieldConfigArgument{
+					"label": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					label, _ := params.Args["label"].(string)
+					if strings.TrimSpace(label) == "" {
+						return nil, NewCodedError(ErrCodeValidationError, "label must not be empty")
+					}
+					todoMu.Lock()
+					defer todoMu.Unlock()
+					todos, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					return CreateSnapshot(label, todos, time.Now().UTC()), nil
+				},
+			},
+
+			"restoreSnapshot": &graphql.Field{
+				Type:        graphql.NewList(todoType),
+				Description: "Admin: replace the todo list with a saved snapshot, returning the restored todos",
+				Args: graphql.FieldConfigArgument{
+					"label": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					label, _ := params.Args["label"].(string)
+					todoMu.Lock()
+					defer todoMu.Unlock()
+					todos, ok := RestoreSnapshot(label)
+					if !ok {
+						return nil, NewCodedError(ErrCodeNotFound, "snapshot %q not found", label)
+					}
+					current, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					bumpRestoredVersions(todos, current, time.Now().UTC())
+					if err := store.Replace(todos); err != nil {
+						return nil, storeError(err)
+					}
+					// subscribers see the restore as updates of the restored
+					// todos and deletes of the ones the snapshot doesn't hold
+					for _, todo := range todos {
+						todoChanges.Publish(todo)
+					}
+					for _, todo := range removedByRestore(todos, current) {
+						todoChanges.Publish(todo)
+					}
+					return todos, nil
+				},
+			},
+
+			"clearTags": &graphql.Field{
+				Type:        todoBatchResultType,
+				Description: "Remove all tags from several todos in one step",
+				Args: graphql.FieldConfigArgument{
+					"ids": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					ids := stringList(params.Args["ids"])
+
+					todoMu.Lock()
+					defer todoMu.Unlock()
+					now := time.Now().UTC()
+					result := TodoBatchResult{Todos: []Todo{}, NotFound: []string{}}
+					for _, id := range ids {
+						todo, ok, err := store.Get(id)
+						if err != nil {
+							return nil, storeError(err)
+						}
+						if !ok {
+							result.NotFound = append(result.NotFound, id)
+							continue
+						}
+						if len(todo.Tags) == 0 {
+							// nothing to clear, the todo is returned unchanged
+							todo.Tags = []string{}
+							result.Todos = append(result.Todos, todo)
+							continue
+						}
+						todo.Tags = []string{}
+						todo.touch(now)
+						if _, err := store.Update(todo); err != nil {
+							return nil, storeError(err)
+						}
+						todoChanges.Publish(todo)
+						result.Todos = append(result.Todos, todo)
+					}
+					return result, nil
+				},
+			},
+
+			/*
+			   curl -XPOST http://localhost:8080/graphql -H 'Content-Type: application/json' -d '{"query": "mutation M($json: String!) { importTodos(json: $json) }", "variables": {"json": "[{\"text\": \"imported\"}]"}}'
+			*/
+			"importTodos": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "Add todos from a JSON array, as returned by exportTodos, returning how many were imported",
+				Args: graphql.FieldConfigArgument{
+					"json": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					data, _ := params.Args["json"].(string)
+					todos, err := ImportTodos([]byte(data), time.Now().UTC())
+					if err != nil {
+						return nil, NewCodedError(ErrCodeValidationError, "invalid todo JSON: %v", err)
+					}
+					// the store checks the ids and adds the todos in one step, so an
+					// id that is already taken leaves the store unchanged
+					todoMu.Lock()
+					err = store.Add(todos...)
+					todoMu.Unlock()
+					if errors.Is(err, ErrDuplicateID) {
+						return nil, NewCodedError(ErrCodeValidationError, "invalid todo JSON: %v", err)
+					}
+					if err != nil {
+						return nil, storeError(err)
+					}
+					for _, todo := range todos {
+						todoChanges.Publish(todo)
+					}
+					return len(todos), nil
+				},
+			},
+
+			"importTrello": &graphql.Field{
+				Type:        trelloImportResultType,
+				Description: "Create todos from the cards of a Trello board export",
+				Args: graphql.FieldConfigArgument{
+					"json": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					data, _ := params.Args["json"].(string)
+					todos, ids, err := TodosFromTrello([]byte(data), time.Now().UTC())
+					if err != nil {
+						return nil, NewCodedError(ErrCodeValidationError, "invalid Trello export: %v", err)
+					}
+					todoMu.Lock()
+					err = store.Add(todos...)
+					todoMu.Unlock()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					for _, todo := range todos {
+						todoChanges.Publish(todo)
+					}
+					return TrelloImportResult{Imported: len(todos), IDs: ids}, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query=mutation+M{classify(ids:["a","b"],tags:["triage"]){todos{id,tags},notFound}}'
+			*/
+			"classify": &graphql.Field{
+				Type:        todoBatchResultType,
+				Description: "Apply tags to several todos in one step; todos keep the tags they already have",
+				Args: graphql.FieldConfigArgument{
+					"ids": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+					},
+					"tags": &graphql.ArgumentConfig{
+						Type: graphql.NewList(graphql.NewNonNull(graphql.String)),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					ids := stringList(params.Args["ids"])
+					tags := stringList(params.Args["tags"])
+
+					todoMu.Lock()
+					defer todoMu.Unlock()
+					now := time.Now().UTC()
+					result := TodoBatchResult{Todos: []Todo{}, NotFound: []string{}}
+					for _, id := range ids {
+						todo, ok, err := store.Get(id)
+						if err != nil {
+							return nil, storeError(err)
+						}
+						if !ok {
+							result.NotFound = append(result.NotFound, id)
+							continue
+						}
+						// a todo that already has every tag is returned as it is
+						if todo.addTags(tags) {
+							todo.touch(now)
+							if _, err := store.Update(todo); err != nil {
+								return nil, storeError(err)
+							}
+							todoChanges.Publish(todo)
+						}
+						result.Todos = append(result.Todos, todo)
+					}
+					return result, nil
+				},
+			},
+		})),
+	})
+
+	// root query
+	// we just define a trivial example here, since root query is required.
+	// Test with curl
+	// curl -g 'http://localhost:8080/graphql?query={lastTodo{id,text,done}}'
+	var rootQuery = graphql.NewObject(graphql.ObjectConfig{
+		Name: "RootQuery",
+		Fields: withoutDeniedFields(denied, graphql.Fields{
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query={todo(id:"b"){id,text,done}}'
+			*/
+			"todo": &graphql.Field{
+				Type:        todoType,
+				Description: "Get single todo",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+
+					idQuery, isOK := params.Args["id"].(string)
+					if isOK {
+						todoMu.RLock()
+						defer todoMu.RUnlock()
+						// Search for el with id
+						todo, ok, err := store.Get(idQuery)
+						if err != nil {
+							return nil, storeError(err)
+						}
+						if ok {
+							return todo, nil
+						}
+					}
+
+					return Todo{}, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query={todosByIds(ids:["c","missing","a"]){id,text}}'
+			*/
+			"todosByIds": &graphql.Field{
+				Type:        graphql.NewList(todoType),
+				Description: "The todos with the given ids, one entry per id in the same order; null for ids that don't exist",
+				Args: graphql.FieldConfigArgument{
+					"ids": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					ids := stringList(params.Args["ids"])
+					todoMu.RLock()
+					defer todoMu.RUnlock()
+					todos := make([]interface{}, len(ids))
+					for i, id := range ids {
+						todo, ok, err := store.Get(id)
+						if err != nil {
+							return nil, storeError(err)
+						}
+						if ok {
+							todos[i] = todo
+						}
+					}
+					return todos, nil
+				},
+			},
+
+			"lastTodo": &graphql.Field{
+				Type:        todoType,
+				Description: "Last todo added that is not archived, or null when there is none",
+				Resolve: safeResolve(func(params graphql.ResolveParams) (interface{}, error) {
+					todoMu.RLock()
+					defer todoMu.RUnlock()
+					todos, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					for i := len(todos) - 1; i >= 0; i-- {
+						if !todos[i].Archived {
+							return todos[i], nil
+						}
+					}
+					return nil, nil
+				}),
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query={todoList{id,text,done}}'
+			*/
+			"todoList": &graphql.Field{
+				Type:        graphql.NewList(todoType),
+				Description: "List of todos",
+				Args: graphql.FieldConfigArgument{
+					"includeArchived": &graphql.ArgumentConfig{
+						Type:         graphql.Boolean,
+						DefaultValue: false,
+					},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					includeArchived, _ := p.Args["includeArchived"].(bool)
+					todoMu.RLock()
+					defer todoMu.RUnlock()
+					all, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					if includeArchived {
+						return all, nil
+					}
+					todos := []Todo{}
+					for _, todo := range all {
+						if !todo.Archived {
+							todos = append(todos, todo)
+						}
+					}
+					return todos, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query={exportTodos(format:CSV)}'
+			*/
+			"exportTodos": &graphql.Field{
+				Type:        graphql.String,
+				Description: "The whole todo list rendered in the requested format; the default JSON can be passed back to importTodos",
+				Args: graphql.FieldConfigArgument{
+					"format": &graphql.ArgumentConfig{
+						Type:         exportFormatEnum,
+						DefaultValue: ExportJSON,
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					format, _ := params.Args["format"].(string)
+					todoMu.RLock()
+					defer todoMu.RUnlock()
+					todos, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					export, err := ExportTodos(todos, format)
+					if err != nil {
+						return nil, NewCodedError(ErrCodeValidationError, "%v", err)
+					}
+					return export, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query={creationByHour(timezone:"Europe/Berlin"){hour,count}}'
+			*/
+			"creationByHour": &graphql.Field{
+				Type:        graphql.NewList(hourCountType),
+				Description: "Number of todos created in each hour of the day (0-23)",
+				Args: graphql.FieldConfigArgument{
+					"timezone": &graphql.ArgumentConfig{
+						Type:         graphql.String,
+						DefaultValue: "UTC",
+						Description:  "IANA time zone the hours are counted in",
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					timezone, _ := params.Args["timezone"].(string)
+					loc, err := time.LoadLocation(timezone)
+					if err != nil {
+						return nil, NewCodedError(ErrCodeValidationError, "unknown timezone %q", timezone)
+					}
+					todoMu.RLock()
+					defer todoMu.RUnlock()
+					todos, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					return CreationByHour(todos, loc), nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query={busiestUpcomingDay(timezone:"Europe/Berlin"){day,count}}'
+			*/
+			"busiestUpcomingDay": &graphql.Field{
+				Type:        dayCountType,
+				Description: "The future day with the most todos due, or null when nothing is due",
+				Args: graphql.FieldConfigArgument{
+					"timezone": &graphql.ArgumentConfig{
+						Type:         graphql.String,
+						DefaultValue: "UTC",
+						Description:  "IANA time zone the calendar days are taken in",
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					timezone, _ := params.Args["timezone"].(string)
+					loc, err := time.LoadLocation(timezone)
+					if err != nil {
+						return nil, NewCodedError(ErrCodeValidationError, "unknown timezone %q", timezone)
+					}
+					todoMu.RLock()
+					defer todoMu.RUnlock()
+					todos, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					if busiest := BusiestUpcomingDay(todos, time.Now(), loc); busiest != nil {
+						return busiest, nil
+					}
+					return nil, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query={velocity{weekStart,points}}'
+			*/
+			"velocity": &graphql.Field{
+				Type:        graphql.NewList(weekPointsType),
+				Description: "Story points of done todos per week of completion, oldest week first",
+				Args: graphql.FieldConfigArgument{
+					"timezone": &graphql.ArgumentConfig{
+						Type:         graphql.String,
+						DefaultValue: "UTC",
+						Description:  "IANA time zone the weeks are taken in",
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					timezone, _ := params.Args["timezone"].(string)
+					loc, err := time.LoadLocation(timezone)
+					if err != nil {
+						return nil, NewCodedError(ErrCodeValidationError, "unknown timezone %q", timezone)
+					}
+					todoMu.RLock()
+					defer todoMu.RUnlock()
+					todos, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					return Velocity(todos, loc), nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query={pointsTarget(from:"2026-10-05T00:00:00Z",to:"2026-10-19T00:00:00Z"){total,target,met}}'
+			*/
+			"pointsTarget": &graphql.Field{
+				Type:        pointsTargetResultType,
+				Description: "Whether the story points completed from `from` (inclusive) to `to` (exclusive) reach the configured target",
+				Args: graphql.FieldConfigArgument{
+					"from": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.DateTime),
+					},
+					"to": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.DateTime),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					from, okFrom := params.Args["from"].(time.Time)
+					to, okTo := params.Args["to"].(time.Time)
+					if !okFrom || !okTo {
+						return nil, NewCodedError(ErrCodeValidationError, "from and to must be RFC 3339 date-times")
+					}
+					if to.Before(from) {
+						return nil, NewCodedError(ErrCodeValidationError, "to must not be before from")
+					}
+					todoMu.RLock()
+					defer todoMu.RUnlock()
+					todos, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					total := CompletedPoints(todos, from, to)
+					return PointsTargetResult{Total: total, Target: pointsTarget, Met: total >= pointsTarget}, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query={staleTodos{id,text,updatedAt}}'
+			*/
+			"staleTodos": &graphql.Field{
+				Type:        graphql.NewList(todoType),
+				Description: "Not done, unarchived todos that have not been updated for a while (see -stale-after), least recently updated first",
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					todoMu.RLock()
+					defer todoMu.RUnlock()
+					todos, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					return StaleTodos(todos, time.Now(), staleAfter), nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query={snapshots{label,createdAt,todoCount}}'
+			*/
+			"snapshots": &graphql.Field{
+				Type:        graphql.NewList(snapshotType),
+				Description: "Retained snapshots, oldest first",
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					todoMu.RLock()
+					defer todoMu.RUnlock()
+					return append([]Snapshot{}, snapshots...), nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query={findTodos(done:false,tag:"work"){id,text}}'
+			*/
+			"findTodos": &graphql.Field{
+				Type:        graphql.NewList(todoType),
+				Description: "Todos matching all of the given filters; filters left out match everything",
+				Args: graphql.FieldConfigArgument{
+					"done": &graphql.ArgumentConfig{
+						Type: graphql.Boolean,
+					},
+					"assignee": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"tag": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"text": &graphql.ArgumentConfig{
+						Type:        graphql.String,
+						Description: "Case-insensitive substring of the todo text",
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					filter := TodoFilter{}
+					if done, ok := params.Args["done"].(bool); ok {
+						filter.Done = &done
+					}
+					if assignee, ok := params.Args["assignee"].(string); ok {
+						filter.Assignee = &assignee
+					}
+					if tag, ok := params.Args["tag"].(string); ok {
+						filter.Tag = &tag
+					}
+					if text, ok := params.Args["text"].(string); ok {
+						filter.Text = &text
+					}
+
+					todoMu.RLock()
+					defer todoMu.RUnlock()
+					all, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					todos := []Todo{}
+					for _, todo := range all {
+						if filter.Matches(todo) {
+							todos = append(todos, todo)
+						}
+					}
+					return todos, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query={todosByTag(tag:"work"){id,text,tags}}'
+			*/
+			"todosByTag": &graphql.Field{
+				Type:        graphql.NewList(todoType),
+				Description: "List of todos labelled with the given tag",
+				Args: graphql.FieldConfigArgument{
+					"tag": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					tag, _ := params.Args["tag"].(string)
+					todoMu.RLock()
+					defer todoMu.RUnlock()
+					all, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					todos := []Todo{}
+					for _, todo := range all {
+						if todo.hasTag(tag) {
+							todos = append(todos, todo)
+						}
+					}
+					return todos, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8080/graphql?query={todosByAssignee(assignee:"alice"){id,text,assignee}}'
+			*/
+			"todosByAssignee": &graphql.Field{
+				Type:        graphql.NewList(todoType),
+				Description: "List of todos assigned to a person; pass an empty string for unassigned todos",
+				Args: graphql.FieldConfigArgument{
+					"assignee": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					assignee, _ := params.Args["assignee"].(string)
+					todoMu.RLock()
+					defer todoMu.RUnlock()
+					all, err := store.List()
+					if err != nil {
+						return nil, storeError(err)
+					}
+					todos := []Todo{}
+					for _, todo := range all {
+						if todo.Assignee == assignee {
+							todos = append(todos, todo)
+						}
+					}
+					return todos, nil
+				},
+			},
+		}),
+	})
+
+	// root subscription, delivered over Server-Sent Events by SubscriptionHandler
+	rootSubscription := graphql.NewObject(graphql.ObjectConfig{
+		Name: "RootSubscription",
+		Fields: withoutDeniedFields(denied, graphql.Fields{
+			"todoChanged": &graphql.Field{
+				Type:        todoType,
+				Description: "A todo created, updated or deleted by a mutation",
+				Subscribe: func(params graphql.ResolveParams) (interface{}, error) {
+					changes := todoChanges.Subscribe()
+					go func() {
+						<-params.Context.Done()
+						todoChanges.Unsubscribe(changes)
+					}()
+					return changes, nil
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					// the published todo is the root value of each event
+					return params.Source, nil
+				},
+			},
+		}),
+	})
+
+	if err := checkDeniedFields(denied); err != nil {
+		return graphql.Schema{}, err
+	}
+	if len(rootQuery.Fields()) == 0 {
+		return graphql.Schema{}, fmt.Errorf("deny fields: every RootQuery field is denied, a schema needs at least one")
+	}
+	config := graphql.SchemaConfig{Query: rootQuery}
+	// a root type without fields is invalid, so deny-all leaves the operation out
+	if len(rootMutation.Fields()) > 0 {
+		config.Mutation = rootMutation
+	}
+	if len(rootSubscription.Fields()) > 0 {
+		config.Subscription = rootSubscription
+	}
+
+	// define schema
+	return graphql.NewSchema(config)
+}
+
+// introspectionQuery asks for the full type system of a schema,
+// the same information GraphiQL and code generators work from.
+const introspectionQuery = `
+query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      locations
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType { kind name }
+      }
+    }
+  }
+}
+`
+
+// PrintSchema runs the introspection query against schema and writes the
+// result to w as indented JSON.
+func PrintSchema(w io.Writer, schema graphql.Schema) error {
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: introspectionQuery,
+	})
+	if result.HasErrors() {
+		return fmt.Errorf("introspection failed: %v", result.Errors)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestUnseededStoreIsEmpty(t *testing.T) {
+	resetTodos(t)
+	var data struct{ TodoList []Todo }
+	mustExecute(t, `{todoList{id}}`, &data)
+	if data.TodoList == nil || len(data.TodoList) != 0 {
+		t.Errorf("todoList = %v, want an empty list", data.TodoList)
+	}
+}
+
+func TestSeedTodos(t *testing.T) {
+	resetTodos(t)
+	if err := seedTodos(); err != nil {
+		t.Fatal(err)
+	}
+	todos, _ := store.List()
+	if ids := todoIDs(todos); len(ids) != 3 || ids[0] != "a" || ids[2] != "c" {
+		t.Fatalf("seeded = %v, want [a b c]", ids)
+	}
+
+	// a store that already has todos, like a reopened database, is left alone
+	if err := seedTodos(); err != nil {
+		t.Fatal(err)
+	}
+	if todos, _ = store.List(); len(todos) != 3 {
+		t.Errorf("seeding twice stored %d todos, want 3", len(todos))
+	}
+	resetTodos(t, Todo{ID: "mine", Text: "kept"})
+	if err := seedTodos(); err != nil {
+		t.Fatal(err)
+	}
+	todos, _ = store.List()
+	if ids := todoIDs(todos); len(ids) != 1 || ids[0] != "mine" {
+		t.Errorf("after reseeding = %v, want only mine", ids)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Snapshot is a named copy of the whole todo list.
+type Snapshot struct {
+	Label     string    `json:"label"`
+	CreatedAt time.Time `json:"createdAt"`
+	Todos     []Todo    `json:"-"`
+}
+
+// maxSnapshots is how many snapshots are retained; creating one more drops the oldest.
+var maxSnapshots = 10
+
+// snapshotMutations enables the createSnapshot and restoreSnapshot admin
+// mutations. They are unrestricted once in the schema, so they are left out
+// unless the operator asks for them. Read by BuildSchema.
+var snapshotMutations = false
+
+// snapshots holds the retained snapshots, oldest first. Guarded by todoMu.
+var snapshots []Snapshot
+
+// cloneTodos deep copies todos so later changes to the list don't leak into the copy.
+func cloneTodos(todos []Todo) []Todo {
+	clone := make([]Todo, len(todos))
+	for i, todo := range todos {
+		todo.Tags = append([]string(nil), todo.Tags...)
+		todo.Attachments = append([]Attachment(nil), todo.Attachments...)
+		clone[i] = todo
+	}
+	return clone
+}
+
+// CreateSnapshot stores todos under label, replacing any snapshot with the
+// same label. The caller must hold todoMu.
+func CreateSnapshot(label string, todos []Todo, now time.Time) Snapshot {
+	snapshot := Snapshot{Label: label, CreatedAt: now, Todos: cloneTodos(todos)}
+
+	retained := []Snapshot{}
+	for _, s := range snapshots {
+		if s.Label != label {
+			retained = append(retained, s)
+		}
+	}
+	retained = append(retained, snapshot)
+	if len(retained) > maxSnapshots {
+		retained = retained[len(retained)-maxSnapshots:]
+	}
+	snapshots = retained
+	return snapshot
+}
+
+// RestoreSnapshot returns a copy of the todos of the snapshot named label.
+// It reports false when there is no such snapshot. The caller must hold todoMu.
+func RestoreSnapshot(label string) ([]Todo, bool) {
+	for _, s := range snapshots {
+		if s.Label == label {
+			return cloneTodos(s.Todos), true
+		}
+	}
+	return nil, false
+}
+
+// bumpRestoredVersions moves each restored todo's Version past the one of the
+// todo it replaces in current, so an updateTodo expecting a version from
+// before the restore fails with a conflict instead of writing over it.
+func bumpRestoredVersions(restored, current []Todo, now time.Time) {
+	versions := map[string]int{}
+	for _, todo := range current {
+		versions[StripIDPrefix("todo", todo.ID)] = todo.Version
+	}
+	for i := range restored {
+		if v := versions[StripIDPrefix("todo", restored[i].ID)]; v > restored[i].Version {
+			restored[i].Version = v
+		}
+		restored[i].touch(now)
+	}
+}
+
+// removedByRestore returns the todos of current that restoring restored
+// drops, those without a restored todo of the same ID.
+func removedByRestore(restored, current []Todo) []Todo {
+	kept := map[string]bool{}
+	for _, todo := range restored {
+		kept[StripIDPrefix("todo", todo.ID)] = true
+	}
+	removed := []Todo{}
+	for _, todo := range current {
+		if !kept[StripIDPrefix("todo", todo.ID)] {
+			removed = append(removed, todo)
+		}
+	}
+	return removed
+}
+
+// withoutSnapshotMutations drops the snapshot admin mutations from the root
+// mutation fields unless snapshotMutations enables them.
+func withoutSnapshotMutations(fields graphql.Fields) graphql.Fields {
+	if !snapshotMutations {
+		delete(fields, "createSnapshot")
+		delete(fields, "restoreSnapshot")
+	}
+	return fields
+}
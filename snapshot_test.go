@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	allowSnapshots(t)
+	resetTodos(t,
+		Todo{ID: "a", Text: "first", Tags: []string{"x"}},
+		Todo{ID: "b", Text: "second"},
+	)
+	var created struct{ CreateSnapshot struct{ Label string } }
+	mustExecute(t, `mutation{createSnapshot(label:"before"){label}}`, &created)
+	if created.CreateSnapshot.Label != "before" {
+		t.Fatalf("label = %q", created.CreateSnapshot.Label)
+	}
+
+	mustExecute(t, `mutation{updateTodo(id:"a",done:true){id}}`, &struct{}{})
+	mustExecute(t, `mutation{clearTags(ids:["a"]){notFound}}`, &struct{}{})
+	mustExecute(t, `mutation{createTodo(text:"third",task:"t"){id}}`, &struct{}{})
+	mustExecute(t, `mutation{clearCompleted{id}}`, &struct{}{})
+
+	var data struct{ RestoreSnapshot []Todo }
+	mustExecute(t, `mutation{restoreSnapshot(label:"before"){id,text,done,tags}}`, &data)
+	restored := data.RestoreSnapshot
+	if ids := todoIDs(restored); len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Fatalf("restored = %v, want [a b]", ids)
+	}
+	if restored[0].Done || len(restored[0].Tags) != 1 || restored[0].Tags[0] != "x" {
+		t.Errorf("a = %+v, want it as it was", restored[0])
+	}
+	todos, _ := store.List()
+	if len(todos) != 2 {
+		t.Errorf("stored = %v, want the snapshot's todos", todoIDs(todos))
+	}
+}
+
+func TestRestoreSnapshotUnknown(t *testing.T) {
+	allowSnapshots(t)
+	resetTodos(t)
+	if code := errorCode(t, execute(t, `mutation{restoreSnapshot(label:"nope"){id}}`)); code != ErrCodeNotFound {
+		t.Errorf("code = %q, want %s", code, ErrCodeNotFound)
+	}
+	if code := errorCode(t, execute(t, `mutation{createSnapshot(label:" "){label}}`)); code != ErrCodeValidationError {
+		t.Errorf("empty label: code = %q, want %s", code, ErrCodeValidationError)
+	}
+}
+
+func TestSnapshotRetention(t *testing.T) {
+	allowSnapshots(t)
+	resetTodos(t, Todo{ID: "a", Text: "only"})
+	saved := maxSnapshots
+	maxSnapshots = 2
+	t.Cleanup(func() { maxSnapshots = saved })
+
+	for i := 1; i <= 3; i++ {
+		mustExecute(t, fmt.Sprintf(`mutation{createSnapshot(label:"s%d"){label}}`, i), &struct{}{})
+	}
+	if code := errorCode(t, execute(t, `mutation{restoreSnapshot(label:"s1"){id}}`)); code != ErrCodeNotFound {
+		t.Errorf("oldest snapshot: code = %q, want it dropped", code)
+	}
+	mustExecute(t, `mutation{restoreSnapshot(label:"s3"){id}}`, &struct{}{})
+}
+
+func TestRestoreSnapshotPublishes(t *testing.T) {
+	allowSnapshots(t)
+	resetTodos(t, Todo{ID: "a", Text: "first"}, Todo{ID: "b", Text: "second"})
+	mustExecute(t, `mutation{createSnapshot(label:"before"){label}}`, &struct{}{})
+	var added struct{ CreateTodo Todo }
+	mustExecute(t, `mutation{createTodo(text:"third",task:"t"){id}}`, &added)
+
+	changes := todoChanges.Subscribe()
+	defer todoChanges.Unsubscribe(changes)
+	mustExecute(t, `mutation{restoreSnapshot(label:"before"){id}}`, &struct{}{})
+	published := publishedIDs(changes)
+	want := []string{"a", "b", added.CreateTodo.ID}
+	if fmt.Sprint(published) != fmt.Sprint(want) {
+		t.Errorf("published = %v, want the restored todos and then the removed one %v", published, want)
+	}
+}
+
+func TestSnapshotMutationsDisabled(t *testing.T) {
+	snapshotMutations = false
+	resetTodos(t, Todo{ID: "a", Text: "only"})
+	for _, query := range []string{
+		`mutation{createSnapshot(label:"before"){label}}`,
+		`mutation{restoreSnapshot(label:"before"){id}}`,
+	} {
+		if result := execute(t, query); !result.HasErrors() {
+			t.Errorf("%s: want it rejected without -snapshots", query)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotsQuery(t *testing.T) {
+	allowSnapshots(t)
+	resetTodos(t, Todo{ID: "a", Text: "one"})
+	var data struct {
+		Snapshots []struct {
+			Label     string
+			CreatedAt time.Time
+			TodoCount int
+		}
+	}
+	mustExecute(t, `{snapshots{label}}`, &data)
+	if len(data.Snapshots) != 0 {
+		t.Fatalf("snapshots = %v, want none yet", data.Snapshots)
+	}
+
+	before := time.Now().UTC().Add(-time.Second)
+	mustExecute(t, `mutation{createSnapshot(label:"one todo"){label}}`, &struct{}{})
+	mustExecute(t, `mutation{createTodo(text:"two",task:"t"){id}}`, &struct{}{})
+	mustExecute(t, `mutation{createSnapshot(label:"two todos"){label}}`, &struct{}{})
+
+	mustExecute(t, `{snapshots{label,createdAt,todoCount}}`, &data)
+	if len(data.Snapshots) != 2 {
+		t.Fatalf("snapshots = %v, want 2", data.Snapshots)
+	}
+	for i, want := range []struct {
+		label string
+		count int
+	}{{"one todo", 1}, {"two todos", 2}} {
+		got := data.Snapshots[i]
+		if got.Label != want.label || got.TodoCount != want.count {
+			t.Errorf("snapshot %d = %+v, want %s with %d todos", i, got, want.label, want.count)
+		}
+		if got.CreatedAt.Before(before) {
+			t.Errorf("snapshot %d createdAt = %v, want the time it was taken", i, got.CreatedAt)
+		}
+	}
+
+	// taking a snapshot again under a label replaces it and moves it last
+	mustExecute(t, `mutation{createSnapshot(label:"one todo"){label}}`, &struct{}{})
+	mustExecute(t, `{snapshots{label,todoCount}}`, &data)
+	if len(data.Snapshots) != 2 || data.Snapshots[1].Label != "one todo" || data.Snapshots[1].TodoCount != 2 {
+		t.Errorf("snapshots = %+v, want the relabelled one last with 2 todos", data.Snapshots)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	// cgo-free SQLite driver, registered as "sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore keeps the todos in a SQLite database, so they survive restarts.
+// Each todo is stored as its JSON encoding next to its id and list position.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens the database at dsn, e.g. "todos.db" or
+// "file:todos.db?_pragma=busy_timeout(5000)", creating the todos table if needed.
+func NewSQLiteStore(dsn string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite has a single writer; one connection also keeps a ":memory:"
+	// database from being a different, empty database per connection.
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS todos (
+		id       TEXT PRIMARY KEY,
+		position INTEGER NOT NULL,
+		data     TEXT NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create todos table: %v", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// todoIDForms returns the ids sameTodoID considers equal to id, to match
+// stored ids with or without the todo prefix.
+func todoIDForms(id string) []interface{} {
+	bare := StripIDPrefix("todo", id)
+	return []interface{}{bare, idPrefixes["todo"] + bare}
+}
+
+// insertTodos inserts todos after the last position of the table inside tx.
+func insertTodos(tx *sql.Tx, todos []Todo) error {
+	err := checkUniqueIDs(todos, func(id string) (bool, error) {
+		var n int
+		err := tx.QueryRow(`SELECT COUNT(*) FROM todos WHERE id IN (?, ?)`, todoIDForms(id)...).Scan(&n)
+		return n > 0, err
+	})
+	if err != nil {
+		return err
+	}
+	var next int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(position), -1) + 1 FROM todos`).Scan(&next); err != nil {
+		return err
+	}
+	for i, todo := range todos {
+		data, err := json.Marshal(todo)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO todos (id, position, data) VALUES (?, ?, ?)`, todo.ID, next+i, string(data)); err != nil {
+			return fmt.Errorf("insert todo %q: %v", todo.ID, err)
+		}
+	}
+	return nil
+}
+
+// inTx runs fn in a transaction, committing when it returns nil.
+func (s *SQLiteStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Add implements Store.
+func (s *SQLiteStore) Add(todos ...Todo) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		return insertTodos(tx, todos)
+	})
+}
+
+// Get implements Store.
+func (s *SQLiteStore) Get(id string) (Todo, bool, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM todos WHERE id IN (?, ?) ORDER BY position LIMIT 1`, todoIDForms(id)...).Scan(&data)
+	if err == sql.ErrNoRows {
+		return Todo{}, false, nil
+	}
+	if err != nil {
+		return Todo{}, false, err
+	}
+	var todo Todo
+	if err := json.Unmarshal([]byte(data), &todo); err != nil {
+		return Todo{}, false, fmt.Errorf("decode todo %q: %v", id, err)
+	}
+	return todo, true, nil
+}
+
+// Update implements Store.
+func (s *SQLiteStore) Update(todo Todo) (bool, error) {
+	data, err := json.Marshal(todo)
+	if err != nil {
+		return false, err
+	}
+	res, err := s.db.Exec(`UPDATE todos SET data = ? WHERE id = ?`, string(data), todo.ID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Delete implements Store.
+func (s *SQLiteStore) Delete(id string) (bool, error) {
+	res, err := s.db.Exec(`DELETE FROM todos WHERE id IN (?, ?)`, todoIDForms(id)...)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// List implements Store.
+func (s *SQLiteStore) List() ([]Todo, error) {
+	rows, err := s.db.Query(`SELECT data FROM todos ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	todos := []Todo{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var todo Todo
+		if err := json.Unmarshal([]byte(data), &todo); err != nil {
+			return nil, fmt.Errorf("decode todo: %v", err)
+		}
+		todos = append(todos, todo)
+	}
+	return todos, rows.Err()
+}
+
+// Replace implements Store.
+func (s *SQLiteStore) Replace(todos []Todo) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM todos`); err != nil {
+			return err
+		}
+		return insertTodos(tx, todos)
+	})
+}
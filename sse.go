@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// SubscriptionHandler serves GraphQL subscriptions as Server-Sent Events.
+// The subscription is read from the `query` parameter (with optional
+// `operationName`) and every result is sent as one `data:` event until the
+// client disconnects.
+//
+//	curl -N -g 'http://localhost:8080/subscriptions?query=subscription{todoChanged{id,text,done}}'
+func SubscriptionHandler(schema graphql.Schema) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		if query == "" {
+			http.Error(w, "missing query parameter", http.StatusBadRequest)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		results := graphql.Subscribe(graphql.Params{
+			Schema:        schema,
+			RequestString: query,
+			OperationName: r.URL.Query().Get("operationName"),
+			Context:       r.Context(),
+		})
+		for {
+			select {
+			case <-r.Context().Done():
+				// let the executor finish sending so its goroutine can exit
+				go func() {
+					for range results {
+					}
+				}()
+				return
+			case result, more := <-results:
+				if !more {
+					return
+				}
+				data, err := json.Marshal(result)
+				if err != nil {
+					return
+				}
+				fmt.Fprintf(w, "data: %s\n\n", data)
+				flusher.Flush()
+			}
+		}
+	})
+}
+
+// operationType returns the type ("query", "mutation" or "subscription") of
+// the operation of doc that a request naming name runs, or "" when there is
+// no such operation.
+func operationType(doc *ast.Document, name string) string {
+	for _, def := range doc.Definitions {
+		operation, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" || (operation.Name != nil && operation.Name.Value == name) {
+			return operation.Operation
+		}
+	}
+	return ""
+}
+
+// RejectSubscriptions answers 400 Bad Request to subscription operations,
+// which the GraphQL handler would run once as a query and answer with
+// nulls, and points the client to /subscriptions instead.
+func RejectSubscriptions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, parsed, err := parseRequest(r)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		if parsed.Document != nil && operationType(parsed.Document, parsed.Options.OperationName) == ast.OperationTypeSubscription {
+			writeGraphQLError(w, http.StatusBadRequest, "subscriptions are served as Server-Sent Events on /subscriptions")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
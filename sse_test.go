@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionTodoChanged(t *testing.T) {
+	resetTodos(t)
+	schema, err := BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(SubscriptionHandler(schema))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	target := server.URL + "/subscriptions?query=" + url.QueryEscape(`subscription{todoChanged{id,text,done}}`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	events := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				select {
+				case events <- data:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+		close(events)
+	}()
+
+	// the handler subscribes after sending the headers, so keep creating a
+	// todo until the subscription sees one
+	tick := time.NewTicker(50 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			mustExecute(t, `mutation{createTodo(text:"live",task:"t"){id}}`, &struct{}{})
+		case data, ok := <-events:
+			if !ok {
+				t.Fatal("stream ended without an event")
+			}
+			var event struct {
+				Data struct{ TodoChanged Todo }
+			}
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				t.Fatalf("event %s: %v", data, err)
+			}
+			if todo := event.Data.TodoChanged; todo.Text != "live" || todo.ID == "" || todo.Done {
+				t.Errorf("event todo = %+v, want the created todo", todo)
+			}
+			return
+		case <-ctx.Done():
+			t.Fatal("no event within the timeout")
+		}
+	}
+}
+
+func TestSubscriptionMissingQuery(t *testing.T) {
+	schema, err := BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	SubscriptionHandler(schema).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/subscriptions", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestRejectSubscriptions(t *testing.T) {
+	resetTodos(t)
+	h := RejectSubscriptions(graphqlHandler(t))
+	tests := []struct {
+		name   string
+		params string
+		status int
+	}{
+		{"subscription", "query=" + url.QueryEscape(`subscription{todoChanged{id}}`), http.StatusBadRequest},
+		{"named subscription", "operationName=S&query=" + url.QueryEscape(`query Q{todoList{id}} subscription S{todoChanged{id}}`), http.StatusBadRequest},
+		{"query beside a subscription", "operationName=Q&query=" + url.QueryEscape(`query Q{todoList{id}} subscription S{todoChanged{id}}`), http.StatusOK},
+		{"query", "query=" + url.QueryEscape(`{todoList{id}}`), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?"+tt.params, nil))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status == http.StatusBadRequest && !strings.Contains(w.Body.String(), "/subscriptions") {
+				t.Errorf("body = %s, want it to point to /subscriptions", w.Body)
+			}
+		})
+	}
+}
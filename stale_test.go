@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStaleTodos(t *testing.T) {
+	now := time.Now().UTC()
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+	resetTodos(t,
+		Todo{ID: "fresh", UpdatedAt: daysAgo(1)},
+		Todo{ID: "old", UpdatedAt: daysAgo(20)},
+		Todo{ID: "oldest", UpdatedAt: daysAgo(90)},
+		Todo{ID: "old but done", Done: true, UpdatedAt: daysAgo(60)},
+		Todo{ID: "borderline", UpdatedAt: daysAgo(13)},
+	)
+
+	var data struct{ StaleTodos []Todo }
+	mustExecute(t, `{staleTodos{id}}`, &data)
+	if got := strings.Join(todoIDs(data.StaleTodos), ","); got != "oldest,old" {
+		t.Errorf("staleTodos = %s, want oldest,old", got)
+	}
+
+	saved := staleAfter
+	staleAfter = 7 * 24 * time.Hour
+	t.Cleanup(func() { staleAfter = saved })
+	mustExecute(t, `{staleTodos{id}}`, &data)
+	if got := strings.Join(todoIDs(data.StaleTodos), ","); got != "oldest,old,borderline" {
+		t.Errorf("with -stale-after 7d staleTodos = %s", got)
+	}
+}
+
+func TestStaleTodosNone(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", UpdatedAt: time.Now().UTC()})
+	var data struct{ StaleTodos []Todo }
+	mustExecute(t, `{staleTodos{id}}`, &data)
+	if data.StaleTodos == nil || len(data.StaleTodos) != 0 {
+		t.Errorf("staleTodos = %v, want an empty list", data.StaleTodos)
+	}
+}
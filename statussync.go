@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// StatusAdapter looks up the state of the external item a todo's ExternalRef
+// links to. Adapters for other trackers are added to statusAdapters.
+type StatusAdapter interface {
+	// Closed reports whether the item at ref is closed. It returns
+	// ErrUnsupportedRef for refs the adapter does not know how to look up.
+	Closed(ctx context.Context, ref string) (bool, error)
+}
+
+var (
+	// ErrUnsupportedRef is returned by adapters for refs of another tracker;
+	// those todos are skipped without logging.
+	ErrUnsupportedRef = errors.New("unsupported external ref")
+	// ErrRateLimited is returned by adapters when the tracker asks to slow
+	// down; the current sync run stops and the rest waits for the next one.
+	ErrRateLimited = errors.New("rate limited by external tracker")
+)
+
+// statusAdapters builds the adapters selectable with -status-sync, given the
+// API token from -status-sync-token.
+var statusAdapters = map[string]func(token string) StatusAdapter{
+	"github": func(token string) StatusAdapter {
+		return &GitHubAdapter{Client: http.DefaultClient, Token: token}
+	},
+}
+
+// statusAdapterNames returns the names of statusAdapters, sorted.
+func statusAdapterNames() []string {
+	names := make([]string, 0, len(statusAdapters))
+	for name := range statusAdapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StatusSync marks todos done once the external item linked by their
+// ExternalRef is closed.
+type StatusSync struct {
+	Adapter StatusAdapter
+	// Limiter spaces out the lookups of a run; nil means no limit.
+	Limiter *rate.Limiter
+	// Timeout bounds each lookup; zero means no timeout.
+	Timeout time.Duration
+}
+
+// Run looks up every not done todo with an ExternalRef and marks the ones whose
+// external item is closed as done, returning them. Lookups happen without
+// holding todoMu, so a todo changed meanwhile is re-read before it is updated.
+func (s *StatusSync) Run(ctx context.Context) []Todo {
+	todoMu.RLock()
+	todos, err := store.List()
+	todoMu.RUnlock()
+	if err != nil {
+		log.Printf("status sync: %v", err)
+		return nil
+	}
+
+	done := []Todo{}
+	for _, todo := range todos {
+		if todo.Done || todo.ExternalRef == "" {
+			continue
+		}
+		if s.Limiter != nil {
+			if err := s.Limiter.Wait(ctx); err != nil {
+				break
+			}
+		}
+		closed, err := s.lookup(ctx, todo.ExternalRef)
+		if errors.Is(err, ErrUnsupportedRef) {
+			continue
+		}
+		if err != nil {
+			log.Printf("status sync: todo %q: %v", todo.ID, err)
+			if errors.Is(err, ErrRateLimited) {
+				break
+			}
+			continue
+		}
+		if !closed {
+			continue
+		}
+		if updated, ok := s.markDone(todo.ID, todo.ExternalRef); ok {
+			done = append(done, updated)
+		}
+	}
+	return done
+}
+
+// lookup asks the adapter about ref, giving up after s.Timeout.
+func (s *StatusSync) lookup(ctx context.Context, ref string) (bool, error) {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	return s.Adapter.Closed(ctx, ref)
+}
+
+// markDone marks the todo done unless it was completed or relinked since it was
+// looked up.
+func (s *StatusSync) markDone(id, ref string) (Todo, bool) {
+	todoMu.Lock()
+	defer todoMu.Unlock()
+	todo, ok, err := store.Get(id)
+	if err != nil {
+		log.Printf("status sync: %v", err)
+		return Todo{}, false
+	}
+	if !ok || todo.Done || todo.ExternalRef != ref {
+		return Todo{}, false
+	}
+	now := time.Now().UTC()
+	todo.setDone(true, now)
+	todo.touch(now)
+	if _, err := store.Update(todo); err != nil {
+		log.Printf("status sync: %v", err)
+		return Todo{}, false
+	}
+	completionDigest.Record(todo, now)
+	todoChanges.Publish(todo)
+	return todo, true
+}
+
+// Start runs the sync every interval until the process exits.
+func (s *StatusSync) Start(interval time.Duration) {
+	go func() {
+		for range time.Tick(interval) {
+			s.Run(context.Background())
+		}
+	}()
+}
+
+// GitHubAdapter looks up GitHub issues and pull requests linked as
+// https://github.com/<owner>/<repo>/issues/<n> or .../pull/<n>.
+type GitHubAdapter struct {
+	Client *http.Client
+	// Token is sent as a bearer token when set, for private repositories and
+	// a higher rate limit.
+	Token string
+	// BaseURL of the API, https://api.github.com when empty.
+	BaseURL string
+}
+
+// Closed implements StatusAdapter.
+func (a *GitHubAdapter) Closed(ctx context.Context, ref string) (bool, error) {
+	u, err := url.Parse(ref)
+	if err != nil || u.Host != "github.com" {
+		return false, ErrUnsupportedRef
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 4 || (parts[2] != "issues" && parts[2] != "pull") {
+		return false, ErrUnsupportedRef
+	}
+	base := a.BaseURL
+	if base == "" {
+		base = "https://api.github.com"
+	}
+	// pull requests are issues too as far as their state is concerned
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/issues/%s", base, parts[0], parts[1], parts[3])
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if a.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	}
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		return false, ErrRateLimited
+	case resp.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("GET %s: %s", endpoint, resp.Status)
+	}
+	var issue struct {
+		State string `json:"state"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&issue); err != nil {
+		return false, fmt.Errorf("decode %s: %v", endpoint, err)
+	}
+	return issue.State == "closed", nil
+}
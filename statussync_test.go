@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubAdapter answers Closed from a map of refs; refs it doesn't know are
+// unsupported. It records the refs looked up.
+type stubAdapter struct {
+	mu     sync.Mutex
+	closed map[string]bool
+	err    map[string]error
+	asked  []string
+}
+
+func (a *stubAdapter) Closed(ctx context.Context, ref string) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.asked = append(a.asked, ref)
+	if err := a.err[ref]; err != nil {
+		return false, err
+	}
+	closed, ok := a.closed[ref]
+	if !ok {
+		return false, ErrUnsupportedRef
+	}
+	return closed, nil
+}
+
+func TestStatusSyncRun(t *testing.T) {
+	resetTodos(t,
+		Todo{ID: "a", Text: "closed issue", ExternalRef: "ref/closed", Version: 1},
+		Todo{ID: "b", Text: "open issue", ExternalRef: "ref/open", Version: 1},
+		Todo{ID: "c", Text: "other tracker", ExternalRef: "ref/unknown", Version: 1},
+		Todo{ID: "d", Text: "already done", ExternalRef: "ref/closed-too", Done: true, Version: 1},
+		Todo{ID: "e", Text: "not linked", Version: 1},
+	)
+	adapter := &stubAdapter{closed: map[string]bool{"ref/closed": true, "ref/open": false, "ref/closed-too": true}}
+	changes := todoChanges.Subscribe()
+	defer todoChanges.Unsubscribe(changes)
+
+	done := (&StatusSync{Adapter: adapter}).Run(context.Background())
+	if ids := todoIDs(done); len(ids) != 1 || ids[0] != "a" {
+		t.Fatalf("done = %v, want [a]", ids)
+	}
+	a, _, _ := store.Get("a")
+	if !a.Done || a.CompletedAt == nil || a.Version != 2 {
+		t.Errorf("a = %+v, want it done at version 2", a)
+	}
+	if b, _, _ := store.Get("b"); b.Done {
+		t.Error("b was marked done though its issue is open")
+	}
+	if got := strings.Join(adapter.asked, " "); got != "ref/closed ref/open ref/unknown" {
+		t.Errorf("looked up %s, want only the not done linked todos", got)
+	}
+	if ids := publishedIDs(changes); len(ids) != 1 || ids[0] != "a" {
+		t.Errorf("published %v, want [a]", ids)
+	}
+}
+
+func TestStatusSyncStopsWhenRateLimited(t *testing.T) {
+	resetTodos(t,
+		Todo{ID: "a", ExternalRef: "ref/limited"},
+		Todo{ID: "b", ExternalRef: "ref/closed"},
+	)
+	adapter := &stubAdapter{
+		closed: map[string]bool{"ref/closed": true},
+		err:    map[string]error{"ref/limited": ErrRateLimited},
+	}
+	if done := (&StatusSync{Adapter: adapter}).Run(context.Background()); len(done) != 0 {
+		t.Errorf("done = %v, want the run stopped", todoIDs(done))
+	}
+	if len(adapter.asked) != 1 {
+		t.Errorf("looked up %v after being rate limited", adapter.asked)
+	}
+}
+
+func TestStatusSyncSkipsRelinkedTodo(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", ExternalRef: "ref/closed"})
+	syncer := &StatusSync{Adapter: &stubAdapter{closed: map[string]bool{"ref/closed": true}}}
+	// the todo was relinked between the lookup and the update
+	if todo, ok := syncer.markDone("a", "ref/old"); ok {
+		t.Errorf("markDone = %+v, want the relinked todo left alone", todo)
+	}
+}
+
+func TestGitHubAdapter(t *testing.T) {
+	var gotAuth, gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotPath = r.Header.Get("Authorization"), r.URL.Path
+		switch r.URL.Path {
+		case "/repos/o/r/issues/1":
+			w.Write([]byte(`{"state":"closed"}`))
+		case "/repos/o/r/issues/2":
+			w.Write([]byte(`{"state":"open"}`))
+		case "/repos/o/r/issues/3":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+		case "/repos/o/r/issues/4":
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte(`{"state":"closed"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	adapter := &GitHubAdapter{Client: server.Client(), Token: "tok", BaseURL: server.URL}
+	ctx := context.Background()
+
+	if closed, err := adapter.Closed(ctx, "https://github.com/o/r/issues/1"); err != nil || !closed {
+		t.Errorf("closed issue = %v, %v", closed, err)
+	}
+	if gotAuth != "Bearer tok" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if closed, err := adapter.Closed(ctx, "https://github.com/o/r/pull/2"); err != nil || closed {
+		t.Errorf("open pull request = %v, %v", closed, err)
+	}
+	if gotPath != "/repos/o/r/issues/2" {
+		t.Errorf("pull request looked up at %s, want the issues endpoint", gotPath)
+	}
+	if _, err := adapter.Closed(ctx, "https://github.com/o/r/issues/3"); err != ErrRateLimited {
+		t.Errorf("rate limited: err = %v", err)
+	}
+	if _, err := adapter.Closed(ctx, "https://github.com/o/r/issues/99"); err == nil {
+		t.Error("missing issue: no error")
+	}
+	for _, ref := range []string{"https://gitlab.com/o/r/issues/1", "https://github.com/o/r", "https://github.com/o/r/wiki/1"} {
+		if _, err := adapter.Closed(ctx, ref); err != ErrUnsupportedRef {
+			t.Errorf("%s: err = %v, want ErrUnsupportedRef", ref, err)
+		}
+	}
+
+	syncer := &StatusSync{Adapter: adapter, Timeout: 20 * time.Millisecond}
+	if _, err := syncer.lookup(ctx, "https://github.com/o/r/issues/4"); err == nil {
+		t.Error("slow lookup: no timeout error")
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Store keeps the todos in list order. Ids are matched with sameTodoID, so
+// callers may pass them with or without the todo prefix.
+//
+// Ids are unique: Add and Replace fail with ErrDuplicateID, storing nothing,
+// when a todo's id is taken by a stored todo or by another todo of the call.
+//
+// A Store only keeps single calls consistent; resolvers that read and then
+// write take todoMu around the whole change.
+type Store interface {
+	// Add appends todos to the end of the list.
+	Add(todos ...Todo) error
+	// Get returns the todo with the given id and whether there is one.
+	Get(id string) (Todo, bool, error)
+	// Update replaces the stored todo having todo's id, reporting false when there is none.
+	Update(todo Todo) (bool, error)
+	// Delete removes the todo with the given id, reporting false when there is none.
+	Delete(id string) (bool, error)
+	// List returns all todos in list order.
+	List() ([]Todo, error)
+	// Replace swaps the whole list for todos, used to reorder it or restore a snapshot.
+	Replace(todos []Todo) error
+}
+
+// ErrDuplicateID is returned by Add and Replace for a todo id that is taken.
+var ErrDuplicateID = errors.New("duplicate todo id")
+
+// checkUniqueIDs returns an ErrDuplicateID error for the first todo whose id
+// repeats an earlier one of todos or is taken.
+func checkUniqueIDs(todos []Todo, taken func(id string) (bool, error)) error {
+	seen := map[string]bool{}
+	for _, todo := range todos {
+		key := StripIDPrefix("todo", todo.ID)
+		if seen[key] {
+			return fmt.Errorf("%w %q", ErrDuplicateID, todo.ID)
+		}
+		seen[key] = true
+		isTaken, err := taken(todo.ID)
+		if err != nil {
+			return err
+		}
+		if isTaken {
+			return fmt.Errorf("%w %q", ErrDuplicateID, todo.ID)
+		}
+	}
+	return nil
+}
+
+// store is the Store the resolvers operate on, chosen with the -db flag.
+var store Store = NewMemStore()
+
+// OpenStore returns the store named by dsn: "mem" keeps the todos in memory,
+// anything else is taken as a SQLite data source name.
+func OpenStore(dsn string) (Store, error) {
+	if strings.TrimSpace(dsn) == "" || dsn == "mem" {
+		return NewMemStore(), nil
+	}
+	return NewSQLiteStore(dsn)
+}
+
+// MemStore keeps the todos in memory; they are lost when the process exits.
+type MemStore struct {
+	mu    sync.RWMutex
+	todos []Todo
+}
+
+// NewMemStore returns an empty MemStore.
+func NewMemStore() *MemStore {
+	return &MemStore{}
+}
+
+// indexOf returns the position of the todo with the given id, or -1.
+// The caller must hold s.mu.
+func (s *MemStore) indexOf(id string) int {
+	for i := range s.todos {
+		if sameTodoID(s.todos[i].ID, id) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Add implements Store. Todos are copied in and out so callers never share
+// the Tags or Attachments of the stored todos.
+func (s *MemStore) Add(todos ...Todo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := checkUniqueIDs(todos, func(id string) (bool, error) {
+		return s.indexOf(id) >= 0, nil
+	})
+	if err != nil {
+		return err
+	}
+	s.todos = append(s.todos, cloneTodos(todos)...)
+	return nil
+}
+
+// Get implements Store.
+func (s *MemStore) Get(id string) (Todo, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := s.indexOf(id)
+	if i < 0 {
+		return Todo{}, false, nil
+	}
+	return cloneTodos(s.todos[i : i+1])[0], true, nil
+}
+
+// Update implements Store.
+func (s *MemStore) Update(todo Todo) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.indexOf(todo.ID)
+	if i < 0 {
+		return false, nil
+	}
+	s.todos[i] = cloneTodos([]Todo{todo})[0]
+	return true, nil
+}
+
+// Delete implements Store.
+func (s *MemStore) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.indexOf(id)
+	if i < 0 {
+		return false, nil
+	}
+	// build a fresh slice so the removed todo is not kept alive by the old backing array
+	remaining := make([]Todo, 0, len(s.todos)-1)
+	remaining = append(remaining, s.todos[:i]...)
+	s.todos = append(remaining, s.todos[i+1:]...)
+	return true, nil
+}
+
+// List implements Store.
+func (s *MemStore) List() ([]Todo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return cloneTodos(s.todos), nil
+}
+
+// Replace implements Store.
+func (s *MemStore) Replace(todos []Todo) error {
+	err := checkUniqueIDs(todos, func(string) (bool, error) {
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.todos = cloneTodos(todos)
+	return nil
+}
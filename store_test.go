@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// storeFactories builds an empty store of every implementation.
+var storeFactories = map[string]func(t *testing.T) Store{
+	"mem": func(t *testing.T) Store {
+		return NewMemStore()
+	},
+	"sqlite": func(t *testing.T) Store {
+		s, err := NewSQLiteStore(filepath.Join(t.TempDir(), "todos.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	},
+}
+
+// forEachStore runs test against every Store implementation, installed as the
+// package store the resolvers use.
+func forEachStore(t *testing.T, test func(t *testing.T, s Store)) {
+	for name, newStore := range storeFactories {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			store = s
+			snapshots = nil
+			t.Cleanup(func() { store = NewMemStore() })
+			test(t, s)
+		})
+	}
+}
+
+func TestStoreContract(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		if err := s.Add(Todo{ID: "a", Text: "one"}, Todo{ID: "b", Text: "two"}); err != nil {
+			t.Fatal(err)
+		}
+		todo, ok, err := s.Get(idPrefixes["todo"] + "a")
+		if err != nil || !ok || todo.Text != "one" {
+			t.Fatalf("Get with prefix = %+v, %v, %v", todo, ok, err)
+		}
+
+		todo.Tags = []string{"kept"}
+		if ok, err := s.Update(todo); err != nil || !ok {
+			t.Fatalf("Update = %v, %v", ok, err)
+		}
+		if ok, err := s.Update(Todo{ID: "missing"}); err != nil || ok {
+			t.Errorf("Update of a missing todo = %v, %v, want false", ok, err)
+		}
+		// changing the returned copy must not change the stored todo
+		todo.Tags[0] = "changed"
+		if got, _, _ := s.Get("a"); !reflect.DeepEqual(got.Tags, []string{"kept"}) {
+			t.Errorf("tags = %v, want [kept]", got.Tags)
+		}
+
+		if err := s.Replace([]Todo{{ID: "b"}, {ID: "a"}, {ID: "c"}}); err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := s.Delete("c"); err != nil || !ok {
+			t.Fatalf("Delete = %v, %v", ok, err)
+		}
+		if ok, _ := s.Delete("c"); ok {
+			t.Error("second Delete reported a todo")
+		}
+		todos, err := s.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := todoIDs(todos); !reflect.DeepEqual(got, []string{"b", "a"}) {
+			t.Errorf("List = %v, want [b a]", got)
+		}
+	})
+}
+
+func TestStoreDuplicateIDs(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s Store) {
+		if err := s.Add(Todo{ID: "a"}); err != nil {
+			t.Fatal(err)
+		}
+		for name, todos := range map[string][]Todo{
+			"taken":             {{ID: "b"}, {ID: "a"}},
+			"taken with prefix": {{ID: idPrefixes["todo"] + "a"}},
+			"repeated in call":  {{ID: "c"}, {ID: "c"}},
+		} {
+			if err := s.Add(todos...); !errors.Is(err, ErrDuplicateID) {
+				t.Errorf("Add %s: err = %v, want ErrDuplicateID", name, err)
+			}
+		}
+		if err := s.Replace([]Todo{{ID: "x"}, {ID: "x"}}); !errors.Is(err, ErrDuplicateID) {
+			t.Errorf("Replace: err = %v, want ErrDuplicateID", err)
+		}
+		// nothing of the failed calls was stored
+		todos, _ := s.List()
+		if got := todoIDs(todos); !reflect.DeepEqual(got, []string{"a"}) {
+			t.Errorf("List = %v, want [a]", got)
+		}
+	})
+}
+
+// resolverScenario is run against every store; the stores must answer each
+// query the same way. Only fields that don't depend on the clock are selected.
+var resolverScenario = []string{
+	`{todoList{id,text,done}}`,
+	`mutation{updateTodo(id:"a",done:true,expectedVersion:1){id,done,version}}`,
+	`mutation{updateTodo(id:"a",done:false,expectedVersion:1){id}}`,
+	`mutation{updateTodo(id:"missing",done:true){id}}`,
+	`mutation{moveTodo(id:"c",toIndex:0){id}}`,
+	`mutation{classify(ids:["b","missing"],tags:["triage"]){todos{id,tags},notFound}}`,
+	`mutation{addAttachment(todoId:"b",filename:"plan.pdf",size:10,contentType:"application/pdf"){id,attachmentCount}}`,
+	`mutation{setStoryPoints(id:"b",points:3){id,storyPoints,version}}`,
+	`mutation{createSnapshot(label:"before"){label,todoCount}}`,
+	`mutation{clearCompleted{id}}`,
+	`{todoList{id,done,tags,storyPoints}}`,
+	`mutation{restoreSnapshot(label:"before"){id,done}}`,
+	`{todo(id:"todo_b"){id,tags,attachments{filename}}}`,
+	`{todosByIds(ids:["c","missing","a"]){id}}`,
+	`mutation{importTodos(json:"[{\"id\":\"a\",\"text\":\"taken\"}]")}`,
+	`mutation{clearTags(ids:["b"]){todos{id,tags}}}`,
+	`mutation{completeAllTodos}`,
+	`{findTodos(done:true){id}}`,
+	`{lastTodo{id,text}}`,
+}
+
+func TestStoreResolverParity(t *testing.T) {
+	allowSnapshots(t)
+	answers := map[string][]string{}
+	forEachStore(t, func(t *testing.T, s Store) {
+		err := s.Add(
+			Todo{ID: "a", Text: "A todo not to forget", Version: 1},
+			Todo{ID: "b", Text: "This is the most important", Version: 1},
+			Todo{ID: "c", Text: "Please do this or else", Version: 1},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, query := range resolverScenario {
+			result := execute(t, query)
+			for i := range result.Errors {
+				// locations don't matter, only messages and codes
+				result.Errors[i].Locations = nil
+			}
+			answer, err := json.Marshal(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			answers[t.Name()] = append(answers[t.Name()], string(answer))
+		}
+	})
+
+	mem, sqlite := answers["TestStoreResolverParity/mem"], answers["TestStoreResolverParity/sqlite"]
+	if len(mem) != len(resolverScenario) || len(sqlite) != len(resolverScenario) {
+		t.Fatalf("got %d and %d answers, want %d each", len(mem), len(sqlite), len(resolverScenario))
+	}
+	for i, query := range resolverScenario {
+		if mem[i] != sqlite[i] {
+			t.Errorf("%s\n mem:    %s\n sqlite: %s", query, mem[i], sqlite[i])
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// allowedStoryPoints are the estimates setStoryPoints accepts.
+var allowedStoryPoints = []int{1, 2, 3, 5, 8}
+
+// parseStoryPoints parses a comma separated list of story point values.
+func parseStoryPoints(list string) ([]int, error) {
+	points := []int{}
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		p, err := strconv.Atoi(field)
+		if err != nil || p <= 0 {
+			return nil, fmt.Errorf("invalid story point value %q", field)
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// validStoryPoints reports whether points is one of the allowed estimates.
+func validStoryPoints(points int) bool {
+	for _, allowed := range allowedStoryPoints {
+		if points == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSetStoryPoints(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "estimate me", Version: 1})
+
+	var data struct{ SetStoryPoints Todo }
+	mustExecute(t, `mutation{setStoryPoints(id:"a",points:5){storyPoints,version}}`, &data)
+	if data.SetStoryPoints.StoryPoints != 5 || data.SetStoryPoints.Version != 2 {
+		t.Errorf("todo = %+v, want 5 points at version 2", data.SetStoryPoints)
+	}
+
+	for _, points := range []int{0, 4, -1, 13} {
+		query := fmt.Sprintf(`mutation{setStoryPoints(id:"a",points:%d){id}}`, points)
+		if code := errorCode(t, execute(t, query)); code != ErrCodeValidationError {
+			t.Errorf("%d points: code = %q, want %s", points, code, ErrCodeValidationError)
+		}
+	}
+	if todo, _, _ := store.Get("a"); todo.StoryPoints != 5 {
+		t.Errorf("storyPoints = %d, want the valid value kept", todo.StoryPoints)
+	}
+	if code := errorCode(t, execute(t, `mutation{setStoryPoints(id:"nope",points:3){id}}`)); code != ErrCodeNotFound {
+		t.Errorf("unknown id: code = %q, want %s", code, ErrCodeNotFound)
+	}
+}
+
+func TestSetStoryPointsConfiguredSet(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "estimate me"})
+	points, err := parseStoryPoints(" 1, 13 ,,21")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := allowedStoryPoints
+	allowedStoryPoints = points
+	t.Cleanup(func() { allowedStoryPoints = saved })
+
+	mustExecute(t, `mutation{setStoryPoints(id:"a",points:13){id}}`, &struct{}{})
+	if code := errorCode(t, execute(t, `mutation{setStoryPoints(id:"a",points:5){id}}`)); code != ErrCodeValidationError {
+		t.Errorf("5 points: code = %q, want %s", code, ErrCodeValidationError)
+	}
+
+	for _, list := range []string{"1,two", "3,0", "-2"} {
+		if _, err := parseStoryPoints(list); err == nil {
+			t.Errorf("parseStoryPoints(%q) succeeded", list)
+		}
+	}
+}
+
+func TestVelocity(t *testing.T) {
+	// Monday 5 and Monday 12 October 2026
+	at := func(day int) *time.Time {
+		completed := time.Date(2026, 10, day, 10, 0, 0, 0, time.UTC)
+		return &completed
+	}
+	resetTodos(t,
+		Todo{ID: "a", Done: true, CompletedAt: at(5), StoryPoints: 3},
+		Todo{ID: "b", Done: true, CompletedAt: at(11), StoryPoints: 5},
+		Todo{ID: "c", Done: true, CompletedAt: at(12), StoryPoints: 8},
+		Todo{ID: "d", Done: false, StoryPoints: 13},
+		Todo{ID: "e", Done: true, CompletedAt: at(13)},
+	)
+	var data struct{ Velocity []WeekPoints }
+	mustExecute(t, `{velocity{weekStart,points}}`, &data)
+	want := []WeekPoints{{WeekStart: "2026-10-05", Points: 8}, {WeekStart: "2026-10-12", Points: 8}}
+	if len(data.Velocity) != len(want) {
+		t.Fatalf("velocity = %+v, want %+v", data.Velocity, want)
+	}
+	for i := range want {
+		if data.Velocity[i] != want[i] {
+			t.Errorf("velocity[%d] = %+v, want %+v", i, data.Velocity[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestCreateTodoWithTags(t *testing.T) {
+	resetTodos(t)
+	var data struct{ CreateTodo Todo }
+	mustExecute(t, `mutation{createTodo(text:"buy milk",task:"errand",tags:["home","Shopping"]){id,tags}}`, &data)
+	if tags := data.CreateTodo.Tags; len(tags) != 2 || tags[0] != "home" || tags[1] != "Shopping" {
+		t.Errorf("tags = %v, want [home Shopping]", tags)
+	}
+
+	mustExecute(t, `mutation{createTodo(text:"no tags",task:"t"){id,tags}}`, &data)
+	if tags := data.CreateTodo.Tags; len(tags) != 0 {
+		t.Errorf("tags = %v, want none", tags)
+	}
+}
+
+func TestTodosByTag(t *testing.T) {
+	resetTodos(t,
+		Todo{ID: "a", Text: "milk", Tags: []string{"home", "Shopping"}},
+		Todo{ID: "b", Text: "report", Tags: []string{"work"}},
+		Todo{ID: "c", Text: "bread", Tags: []string{"shopping"}},
+	)
+	tests := []struct {
+		tag  string
+		want []string
+	}{
+		{"home", []string{"a"}},
+		{"Shopping", []string{"a"}},
+		{"shopping", []string{"c"}},
+		{"shop", nil},
+		{"garden", nil},
+	}
+	for _, tt := range tests {
+		var data struct{ TodosByTag []Todo }
+		mustExecute(t, `{todosByTag(tag:"`+tt.tag+`"){id}}`, &data)
+		got := todoIDs(data.TodosByTag)
+		if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+			t.Errorf("todosByTag(%q) = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTodosByIds(t *testing.T) {
+	resetTodos(t, Todo{ID: "a", Text: "one"}, Todo{ID: "b", Text: "two"}, Todo{ID: "c", Text: "three"})
+	tests := []struct {
+		name string
+		ids  string
+		want string
+	}{
+		{"order preserved", `["c","a","b"]`, "c a b"},
+		{"missing ids are null", `["a","nope","c","gone"]`, "a - c -"},
+		{"duplicates each answered", `["b","b","a","b"]`, "b b a b"},
+		{"prefixed ids", `["todo_a","c"]`, "a c"},
+		{"empty list", `[]`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data struct{ TodosByIds []*Todo }
+			mustExecute(t, `{todosByIds(ids:`+tt.ids+`){id}}`, &data)
+			if data.TodosByIds == nil {
+				t.Fatal("todosByIds = null, want a list")
+			}
+			got := make([]string, len(data.TodosByIds))
+			for i, todo := range data.TodosByIds {
+				got[i] = "-"
+				if todo != nil {
+					got[i] = todo.ID
+				}
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("todosByIds(%s) = %v, want %s", tt.ids, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// trelloBoard is the part of a Trello board export that is imported.
+// Fields missing from the export decode to their zero values.
+type trelloBoard struct {
+	Lists []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"lists"`
+	Cards []struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		IDList      string `json:"idList"`
+		DueComplete bool   `json:"dueComplete"`
+		Labels      []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	} `json:"cards"`
+}
+
+// IDMapping pairs an id from an imported source with the id of the todo created for it.
+type IDMapping struct {
+	OldID string `json:"oldId"`
+	NewID string `json:"newId"`
+}
+
+// TrelloImportResult is returned by the importTrello mutation.
+type TrelloImportResult struct {
+	Imported int         `json:"imported"`
+	IDs      []IDMapping `json:"ids"`
+}
+
+// TodosFromTrello maps the cards of a Trello board export to new todos:
+// the card name becomes the text, the name of its list the task and its
+// label names the tags. Cards without a name are skipped.
+// Checklists are not imported since todos have no subtasks.
+func TodosFromTrello(data []byte, now time.Time) ([]Todo, []IDMapping, error) {
+	var board trelloBoard
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, nil, err
+	}
+
+	listNames := map[string]string{}
+	for _, list := range board.Lists {
+		listNames[list.ID] = list.Name
+	}
+
+	todos := []Todo{}
+	mappings := []IDMapping{}
+	for _, card := range board.Cards {
+		text := strings.TrimSpace(card.Name)
+		if text == "" {
+			continue
+		}
+		todo := Todo{
+			ID:        NewID("todo"),
+			Text:      text,
+			Task:      listNames[card.IDList],
+			CreatedAt: now,
+			UpdatedAt: now,
+			Version:   1,
+		}
+		todo.setDone(card.DueComplete, now)
+		for _, label := range card.Labels {
+			if label.Name != "" {
+				todo.addTags([]string{label.Name})
+			}
+		}
+		todos = append(todos, todo)
+		mappings = append(mappings, IDMapping{OldID: card.ID, NewID: todo.ID})
+	}
+	return todos, mappings, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+const trelloFixture = `{
+  "name": "Sprint board",
+  "lists": [{"id": "l1", "name": "Backlog"}, {"id": "l2", "name": "Done"}],
+  "cards": [
+    {"id": "c1", "name": "Write spec", "idList": "l1", "labels": [{"name": "docs"}, {"name": ""}]},
+    {"id": "c2", "name": "Ship it", "idList": "l2", "dueComplete": true},
+    {"id": "c3", "name": "  ", "idList": "l1"},
+    {"id": "c4", "name": "Orphan card", "idList": "gone", "checklists": [{"name": "ignored"}]}
+  ]
+}`
+
+func TestImportTrello(t *testing.T) {
+	resetTodos(t)
+	var data struct{ ImportTrello TrelloImportResult }
+	mustExecute(t, `mutation{importTrello(json:`+strconv.Quote(trelloFixture)+`){imported,ids{oldId,newId}}}`, &data)
+	result := data.ImportTrello
+	if result.Imported != 3 || len(result.IDs) != 3 {
+		t.Fatalf("result = %+v, want 3 cards imported, the nameless one skipped", result)
+	}
+
+	want := map[string]struct {
+		text, task string
+		done       bool
+		tags       int
+	}{
+		"c1": {"Write spec", "Backlog", false, 1},
+		"c2": {"Ship it", "Done", true, 0},
+		"c4": {"Orphan card", "", false, 0},
+	}
+	for _, mapping := range result.IDs {
+		w, ok := want[mapping.OldID]
+		if !ok {
+			t.Errorf("unexpected card %q", mapping.OldID)
+			continue
+		}
+		todo, found, _ := store.Get(mapping.NewID)
+		if !found {
+			t.Errorf("card %s: no todo %q", mapping.OldID, mapping.NewID)
+			continue
+		}
+		if todo.Text != w.text || todo.Task != w.task || todo.Done != w.done || len(todo.Tags) != w.tags {
+			t.Errorf("card %s = %+v, want %+v", mapping.OldID, todo, w)
+		}
+		if todo.Done != (todo.CompletedAt != nil) {
+			t.Errorf("card %s: done %v with completedAt %v", mapping.OldID, todo.Done, todo.CompletedAt)
+		}
+	}
+}
+
+func TestImportTrelloMalformed(t *testing.T) {
+	resetTodos(t)
+	if code := errorCode(t, execute(t, `mutation{importTrello(json:"{\"cards\":"){imported}}`)); code != ErrCodeValidationError {
+		t.Errorf("code = %q, want %s", code, ErrCodeValidationError)
+	}
+	if todos, _ := store.List(); len(todos) != 0 {
+		t.Errorf("todos = %v, want nothing imported", todos)
+	}
+
+	var data struct{ ImportTrello TrelloImportResult }
+	mustExecute(t, `mutation{importTrello(json:"{}"){imported}}`, &data)
+	if data.ImportTrello.Imported != 0 {
+		t.Errorf("imported = %d from an empty export", data.ImportTrello.Imported)
+	}
+}
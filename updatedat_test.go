@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdatedAtRefreshedByMutations(t *testing.T) {
+	then := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	mutations := map[string]string{
+		"updateTodo":     `mutation{updateTodo(id:"a",done:true){updatedAt}}`,
+		"reopenTodo":     `mutation{reopenTodo(id:"a"){updatedAt}}`,
+		"setStoryPoints": `mutation{setStoryPoints(id:"a",points:3){updatedAt}}`,
+		"setExternalRef": `mutation{setExternalRef(id:"a",url:"https://example.com/1"){updatedAt}}`,
+		"addAttachment":  `mutation{addAttachment(todoId:"a",filename:"f.txt",size:1,contentType:"text/plain"){updatedAt}}`,
+		"archiveTodo":    `mutation{archiveTodo(id:"a"){updatedAt}}`,
+		"classify":       `mutation{classify(ids:["a"],tags:["new"]){todos{updatedAt}}}`,
+	}
+	for name, query := range mutations {
+		t.Run(name, func(t *testing.T) {
+			resetTodos(t, Todo{ID: "a", Text: "old", Done: true, CompletedAt: &then, CreatedAt: then, UpdatedAt: then, Version: 1})
+			mustExecute(t, query, &struct{}{})
+			todo, _, _ := store.Get("a")
+			if !todo.UpdatedAt.After(then) {
+				t.Errorf("updatedAt = %v, want later than %v", todo.UpdatedAt, then)
+			}
+			if !todo.CreatedAt.Equal(then) {
+				t.Errorf("createdAt = %v, want it kept", todo.CreatedAt)
+			}
+		})
+	}
+}
+
+func TestUpdatedAtSetOnCreate(t *testing.T) {
+	resetTodos(t)
+	var created struct{ CreateTodo Todo }
+	mustExecute(t, `mutation{createTodo(text:"new",task:"t"){id,createdAt,updatedAt}}`, &created)
+	if created.CreateTodo.UpdatedAt.IsZero() || !created.CreateTodo.UpdatedAt.Equal(created.CreateTodo.CreatedAt) {
+		t.Fatalf("todo = %+v, want updatedAt equal to createdAt", created.CreateTodo)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	var updated struct{ UpdateTodo Todo }
+	mustExecute(t, `mutation{updateTodo(id:"`+created.CreateTodo.ID+`",done:true){updatedAt}}`, &updated)
+	if !updated.UpdateTodo.UpdatedAt.After(created.CreateTodo.UpdatedAt) {
+		t.Errorf("updatedAt = %v, want strictly later than %v", updated.UpdateTodo.UpdatedAt, created.CreateTodo.UpdatedAt)
+	}
+}
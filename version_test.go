@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// updateVersion marks todo a done with expectedVersion and returns the result.
+func updateVersion(t *testing.T, expectedVersion int) (Todo, string) {
+	t.Helper()
+	result := execute(t, fmt.Sprintf(`mutation{updateTodo(id:"a",done:true,expectedVersion:%d){id,version}}`, expectedVersion))
+	if result.HasErrors() {
+		return Todo{}, errorCode(t, result)
+	}
+	var data struct{ UpdateTodo Todo }
+	decodeData(t, result, &data)
+	return data.UpdateTodo, ""
+}
+
+func TestUpdateTodoExpectedVersion(t *testing.T) {
+	now := time.Now().UTC()
+	resetTodos(t, Todo{ID: "a", Text: "race", CreatedAt: now, UpdatedAt: now, Version: 3})
+
+	if _, code := updateVersion(t, 2); code != ErrCodeConflict {
+		t.Fatalf("stale version: code = %q, want %s", code, ErrCodeConflict)
+	}
+	if todo, _, _ := store.Get("a"); todo.Done || todo.Version != 3 {
+		t.Errorf("after conflict todo = %+v, want it unchanged", todo)
+	}
+
+	todo, code := updateVersion(t, 3)
+	if code != "" || todo.Version != 4 {
+		t.Fatalf("matching version: todo = %+v, code = %q, want version 4", todo, code)
+	}
+
+	var data struct{ UpdateTodo Todo }
+	mustExecute(t, `mutation{updateTodo(id:"a",done:false){version}}`, &data)
+	if data.UpdateTodo.Version != 5 {
+		t.Errorf("without expectedVersion version = %d, want 5", data.UpdateTodo.Version)
+	}
+}
+
+func TestRestoreSnapshotBumpsVersions(t *testing.T) {
+	allowSnapshots(t)
+	now := time.Now().UTC()
+	resetTodos(t,
+		Todo{ID: "a", Text: "edited", CreatedAt: now, UpdatedAt: now, Version: 1},
+		Todo{ID: "b", Text: "untouched", CreatedAt: now, UpdatedAt: now, Version: 1},
+	)
+	mustExecute(t, `mutation{createSnapshot(label:"before"){label}}`, &struct{}{})
+	mustExecute(t, `mutation{updateTodo(id:"a",done:true){version}}`, &struct{}{})
+	mustExecute(t, `mutation{updateTodo(id:"a",done:false){version}}`, &struct{}{})
+
+	var data struct{ RestoreSnapshot []Todo }
+	mustExecute(t, `mutation{restoreSnapshot(label:"before"){id,version}}`, &data)
+	versions := map[string]int{}
+	for _, todo := range data.RestoreSnapshot {
+		versions[todo.ID] = todo.Version
+	}
+	// a was at 3 before the restore, b at 1
+	if versions["a"] != 4 || versions["b"] != 2 {
+		t.Errorf("restored versions = %v, want a:4 b:2", versions)
+	}
+
+	// versions a client read before or after the edits are all stale now
+	for _, stale := range []int{1, 3} {
+		if _, code := updateVersion(t, stale); code != ErrCodeConflict {
+			t.Errorf("expectedVersion %d: code = %q, want %s", stale, code, ErrCodeConflict)
+		}
+	}
+	if _, code := updateVersion(t, 4); code != "" {
+		t.Errorf("expectedVersion 4: code = %q, want success", code)
+	}
+}